@@ -1,7 +1,13 @@
 package ai
 
 import (
+	"errors"
+	"path/filepath"
+	"time"
+
 	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/profile"
 )
 
 type AIAgent interface {
@@ -9,41 +15,441 @@ type AIAgent interface {
 	IsAvailable() bool
 }
 
+// StreamingAgent is implemented by agents that can deliver a reply
+// incrementally. onChunk receives cleaned text in order; the returned string
+// is the complete cleaned reply.
+type StreamingAgent interface {
+	ProcessCommandStream(input string, context GameContext, onChunk func(string)) (string, error)
+}
+
+const (
+	BackendOllama   = "ollama"
+	BackendOpenAI   = "openai"
+	BackendScripted = "scripted"
+)
+
+var (
+	ErrAIDisabled  = errors.New("AI is disabled")
+	ErrUnavailable = errors.New("AI backend is not reachable")
+	ErrEmptyReply  = errors.New("model returned an empty reply")
+	ErrRejected    = errors.New("reply rejected by guardrails")
+)
+
+// Where a reply came from.
+const (
+	SourceAI       = "ai"
+	SourceCache    = "cache"
+	SourceFallback = "fallback"
+	SourceError    = "error"
+	SourceLegacy   = "legacy"
+)
+
+// Reply is an answer and where it came from. Duration is how long it took
+// to produce; Render is how much longer the player waited for it to be
+// drawn.
+type Reply struct {
+	Text     string
+	Source   string
+	Err      error
+	Trace    *Trace
+	Duration time.Duration
+	Render   time.Duration
+}
+
 type AgentManager struct {
-	agent  AIAgent
-	config *config.Config
+	agent      AIAgent
+	config     *config.Config
+	slots      chan struct{}
+	guardrails *Guardrails
+	cache      *ResponseCache
+	breaker    *Breaker
+	monitor    *Monitor
+	turnLog    *TurnLog
+	metrics    *Metrics
 }
 
 func NewAgentManager(cfg *config.Config) *AgentManager {
-	agent := NewOllamaClient(&cfg.AI)
+	var agent AIAgent
+	switch cfg.AI.Backend {
+	case BackendOpenAI:
+		agent = NewOpenAIClient(&cfg.AI)
+	case BackendScripted:
+		scripted, err := LoadScriptedAgent(cfg.AI.ScriptPath)
+		if err != nil {
+			scripted = newBrokenScriptedAgent(err)
+		}
+		agent = scripted
+	default:
+		agent = NewOllamaClient(&cfg.AI)
+	}
+
+	var slots chan struct{}
+	if cfg.AI.MaxConcurrent > 0 {
+		slots = make(chan struct{}, cfg.AI.MaxConcurrent)
+	}
+
+	breaker := NewBreaker(cfg.AI.BreakerFailures, cfg.AI.BreakerCooldown)
+
+	var turnLog *TurnLog
+	if cfg.AI.Log != "" {
+		turnLog = NewTurnLog(cfg.AI.Log, cfg.AI.LogMaxBytes, cfg.AI.LogMaxFiles)
+	}
 
 	return &AgentManager{
-		agent:  agent,
-		config: cfg,
+		agent:      agent,
+		config:     cfg,
+		slots:      slots,
+		guardrails: NewGuardrails(&cfg.AI),
+		cache:      NewResponseCache(CachePath(cfg), cfg.AI.CacheMaxEntries, cfg.AI.CacheMaxBytes),
+		breaker:    breaker,
+		monitor:    NewMonitor(agent.IsAvailable, breaker, cfg.AI.HealthInterval),
+		turnLog:    turnLog,
+		metrics:    NewMetrics(),
 	}
 }
 
+// CachePath is where replies are cached: ai.cache_dir if set, otherwise a
+// cache directory beside the player profiles.
+func CachePath(cfg *config.Config) string {
+	dir := cfg.AI.CacheDir
+	if dir == "" {
+		dataDir := cfg.Profile.DataDir
+		if dataDir == "" {
+			dataDir = profile.DefaultDir()
+		}
+		dir = filepath.Join(dataDir, "cache")
+	}
+	return filepath.Join(dir, "responses.json")
+}
+
+// acquire waits for a free request slot when concurrency is capped and
+// returns the function that gives it back. Slots cover talking to the
+// backend, not drawing the reply.
+func (am *AgentManager) acquire() func() {
+	if am.slots == nil {
+		return func() {}
+	}
+
+	am.slots <- struct{}{}
+	return func() { <-am.slots }
+}
+
 func (am *AgentManager) ProcessInput(input string, context GameContext) string {
-	// Try AI agent first
-	if am.config.AI.Enabled && am.agent.IsAvailable() {
-		response, err := am.agent.ProcessCommand(input, context)
-		if err == nil && response != "" {
-			return response
+	return am.Reply(input, context, nil).Text
+}
+
+// ProcessInputStream behaves like ProcessInput but hands the reply to onChunk
+// as it is produced, streaming when both the config and the agent allow it.
+func (am *AgentManager) ProcessInputStream(input string, context GameContext, onChunk func(string)) string {
+	return am.Reply(input, context, onChunk).Text
+}
+
+// Reply answers input and reports where the answer came from. onChunk, if
+// not nil, receives the reply as it is produced. Err is why the model could
+// not answer when the reply came from somewhere else.
+func (am *AgentManager) Reply(input string, context GameContext, onChunk func(string)) Reply {
+	start := time.Now()
+	context.Trace = &Trace{}
+
+	// Duration is taken when the backend is done, before the reply has
+	// necessarily been drawn, so it measures the model and not the
+	// typewriter.
+	var reply Reply
+	produce := func(emit func(string)) {
+		reply = am.reply(input, context, emit)
+		reply.Duration = time.Since(start)
+	}
+
+	if onChunk == nil {
+		produce(nil)
+	} else {
+		// The reply is produced on its own goroutine and drawn on this one,
+		// so the request slot is given back as soon as the backend is done.
+		chunks := newRelay()
+		go func() {
+			produce(chunks.Write)
+			chunks.Close()
+		}()
+		chunks.Drain(onChunk)
+		reply.Render = time.Since(start) - reply.Duration
+	}
+	reply.Trace = context.Trace
+
+	am.Record(context.PlayerName, input, reply)
+	return reply
+}
+
+// Record counts a turn in the metrics and adds it to the turn log, if there
+// is one. Reply records its own turns; the game records those it answers
+// without the AI.
+func (am *AgentManager) Record(player, input string, reply Reply) {
+	am.metrics.Observe(reply)
+	if am.turnLog == nil {
+		return
+	}
+
+	am.turnLog.Record(TurnRecord{
+		Player:   player,
+		Input:    input,
+		Model:    am.Model(),
+		Trace:    reply.Trace,
+		Reply:    reply.Text,
+		Source:   reply.Source,
+		Err:      reply.Err,
+		Duration: reply.Duration,
+		Render:   reply.Render,
+	})
+}
+
+// Metrics covers every turn this manager has seen, across all sessions.
+func (am *AgentManager) Metrics() *Metrics {
+	return am.metrics
+}
+
+// TurnLog is the debug log of turns, or nil when ai.log is not set.
+func (am *AgentManager) TurnLog() *TurnLog {
+	return am.turnLog
+}
+
+func (am *AgentManager) reply(input string, context GameContext, onChunk func(string)) Reply {
+	release := am.acquire()
+	defer release()
+
+	emit := onChunk
+	if emit == nil {
+		emit = func(string) {}
+	}
+
+	if am.cacheMode() == CacheFirst {
+		if response, ok := am.Cached(input, context); ok {
+			emit(response)
+			return Reply{Text: response, Source: SourceCache}
+		}
+	}
+
+	err := ErrAIDisabled
+	if am.config.AI.Enabled {
+		err = ErrBreakerOpen
+		if am.breaker.Allow() {
+			var response string
+			var shown bool
+			response, shown, err = am.attempt(input, context, onChunk)
+			am.breaker.Record(err)
+			am.monitor.Observe(err)
+			if err == nil {
+				if !shown {
+					emit(response)
+				}
+				if am.cacheMode() != CacheOff {
+					if err := am.cache.Put(CacheKey(input, context, am.Model()), input, am.Model(), response); err != nil {
+						context.Trace.cacheFailed(err)
+					}
+				}
+				return Reply{Text: response, Source: SourceAI}
+			}
+		}
+	}
+
+	reply := am.recover(input, context, err)
+	emit(reply.Text)
+	return reply
+}
+
+// recover finds something to say when the model could not answer: a cached
+// reply to the same question if the cache is on, otherwise the built-in
+// fallback lines, or the error itself if those are disabled too. Turning
+// the AI off always leaves the fallback lines.
+func (am *AgentManager) recover(input string, context GameContext, err error) Reply {
+	if response, ok := am.Cached(input, context); ok {
+		return Reply{Text: response, Source: SourceCache, Err: err}
+	}
+
+	if am.config.AI.FallbackEnabled || errors.Is(err, ErrAIDisabled) {
+		return Reply{Text: GetFallbackResponse(input, context), Source: SourceFallback, Err: err}
+	}
+
+	return Reply{Text: i18n.For(context.Locale).T("ai.failed", err), Source: SourceError, Err: err}
+}
+
+// attempt asks the agent, retrying transient failures after a jittered
+// backoff. Nothing has reached the player when ask fails, so a retry never
+// repeats part of a reply.
+func (am *AgentManager) attempt(input string, context GameContext, onChunk func(string)) (string, bool, error) {
+	for i := 0; ; i++ {
+		response, shown, err := am.ask(input, context, onChunk)
+		if err == nil || i >= am.config.AI.Retries || !isTransient(err) {
+			return response, shown, err
 		}
+		time.Sleep(backoff(am.config.AI.RetryBackoff, i))
+	}
+}
+
+// ask gets a reply from the agent, streaming it to onChunk when possible.
+// shown reports whether onChunk has already seen the reply. A reply that
+// fails, or that the guardrails reject outright, returns an error before
+// anything has been shown.
+func (am *AgentManager) ask(input string, context GameContext, onChunk func(string)) (string, bool, error) {
+	if streamer, ok := am.agent.(StreamingAgent); ok && am.config.AI.Stream && onChunk != nil {
+		if am.guardrails.Enabled() {
+			return am.guardedStream(streamer, input, context, onChunk)
+		}
+		response, err := streamer.ProcessCommandStream(input, context, onChunk)
+		return response, err == nil, err
+	}
+
+	response, err := am.generate(input, context)
+	return response, false, err
+}
+
+// generate asks the agent for a reply and has the guardrails review it.
+func (am *AgentManager) generate(input string, context GameContext) (string, error) {
+	response, err := am.agent.ProcessCommand(input, context)
+	if err != nil {
+		return "", err
+	}
+
+	return am.guardrails.Review(input, response, context.Trace, func() (string, error) {
+		return am.agent.ProcessCommand(input, context)
+	})
+}
+
+// guardedStream streams a reply through the guardrails' gate. A reply that
+// broke the rules before anything reached the player is resolved by policy;
+// one that broke them later is cut off where it went wrong.
+func (am *AgentManager) guardedStream(streamer StreamingAgent, input string, context GameContext, onChunk func(string)) (string, bool, error) {
+	gate := am.guardrails.gate(onChunk)
+	response, err := streamer.ProcessCommandStream(input, context, gate.Write)
+	shown, violations := gate.Close()
+
+	switch {
+	case shown != "" && len(violations) > 0:
+		am.guardrails.record(input, violations, ActionTrimmed, response, context.Trace)
+		return shown, true, nil
+	case shown != "":
+		return shown, true, nil
+	case err != nil:
+		return "", false, err
+	case len(violations) == 0:
+		return response, false, nil
+	}
+
+	reply, err := am.guardrails.resolve(input, response, violations, context.Trace, func() (string, error) {
+		return am.agent.ProcessCommand(input, context)
+	})
+	return reply, false, err
+}
+
+// Guardrails exposes the reply checks so callers can report interventions.
+func (am *AgentManager) Guardrails() *Guardrails {
+	return am.guardrails
+}
+
+func (am *AgentManager) Cache() *ResponseCache {
+	return am.cache
+}
+
+// Cached returns the cached reply to input, if the cache is on and has one.
+func (am *AgentManager) Cached(input string, context GameContext) (string, bool) {
+	if am.cacheMode() == CacheOff {
+		return "", false
 	}
+	return am.cache.Get(CacheKey(input, context, am.Model()))
+}
 
-	return GetFallbackResponse(input, context)
+func (am *AgentManager) cacheMode() string {
+	switch am.config.AI.Cache {
+	case CacheOffline, CacheFirst:
+		return am.config.AI.Cache
+	default:
+		return CacheOff
+	}
 }
 
+// IsAIAvailable reports whether the AI is worth asking. The health monitor
+// and the breaker decide between them, so no turn waits on a probe. Failed
+// health checks open the breaker; when it never opens, the monitor's last
+// check decides instead, as long as something will check again.
 func (am *AgentManager) IsAIAvailable() bool {
-	return am.config.AI.Enabled && am.agent.IsAvailable()
+	if !am.config.AI.Enabled {
+		return false
+	}
+
+	am.monitor.Start()
+	if !am.breaker.Enabled() && am.config.AI.HealthInterval > 0 {
+		return am.monitor.Health().Online
+	}
+	return am.breaker.Ready()
+}
+
+// Health reports what the monitor knows about the backend, starting it if
+// it is not yet running.
+func (am *AgentManager) Health() Health {
+	if !am.config.AI.Enabled {
+		return Health{Err: ErrAIDisabled}
+	}
+
+	am.monitor.Start()
+	return am.monitor.Health()
+}
+
+// Monitor lets callers hear when the backend goes offline or comes back.
+func (am *AgentManager) Monitor() *Monitor {
+	return am.monitor
 }
 
 func (am *AgentManager) GetStatus() map[string]interface{} {
-	return map[string]interface{}{
+	health := am.Health()
+	status := map[string]interface{}{
 		"ai_enabled":   am.config.AI.Enabled,
-		"ai_available": am.IsAIAvailable(),
-		"model":        am.config.AI.Model,
-		"ollama_url":   am.config.AI.OllamaURL,
+		"ai_available": health.Online,
+		"since":        health.Since,
+		"checked":      health.Checked,
+		"backend":      am.backend(),
+		"model":        am.Model(),
+		"api":          am.config.AI.API,
+		"endpoint":     am.endpoint(),
+		"guardrails":   am.guardrails.Policy(),
+		"cache":        am.cacheMode(),
+	}
+
+	state, _ := am.breaker.State()
+	status["breaker"] = state
+	if am.turnLog != nil {
+		status["log"] = am.turnLog.Path()
+	}
+	if err := am.cache.Err(); err != nil {
+		status["cache_error"] = err.Error()
+	}
+	if health.Err != nil && am.config.AI.Enabled {
+		status["error"] = health.Err.Error()
+	}
+
+	if scripted, ok := am.agent.(*ScriptedAgent); ok && scripted.Err() != nil {
+		status["error"] = scripted.Err().Error()
+	}
+
+	return status
+}
+
+func (am *AgentManager) backend() string {
+	switch am.config.AI.Backend {
+	case BackendOpenAI, BackendScripted:
+		return am.config.AI.Backend
+	default:
+		return BackendOllama
+	}
+}
+
+func (am *AgentManager) endpoint() string {
+	switch am.backend() {
+	case BackendOpenAI:
+		return am.config.AI.OpenAI.BaseURL
+	case BackendScripted:
+		if am.config.AI.ScriptPath == "" {
+			return "(embedded) luna-script.json"
+		}
+		return am.config.AI.ScriptPath
+	default:
+		return am.config.AI.OllamaURL
 	}
 }
@@ -0,0 +1,251 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	CacheOff     = "off"
+	CacheOffline = "offline"
+	CacheFirst   = "first"
+)
+
+// cacheVersion is part of every key so a change to how keys are built
+// retires old entries instead of serving them for the wrong question.
+const cacheVersion = "v1"
+
+// ResponseCache remembers past model replies on disk, keyed by a fingerprint
+// of the question and the moment it was asked, so they can be served again
+// when the model is unreachable or, optionally, before asking it at all.
+// Least recently used entries are evicted to stay within the size limits.
+type ResponseCache struct {
+	mu         sync.Mutex
+	path       string
+	maxEntries int
+	maxBytes   int
+
+	loaded  bool
+	entries map[string]*CacheEntry
+	size    int
+	err     error
+}
+
+type CacheEntry struct {
+	Key      string    `json:"key"`
+	Input    string    `json:"input"`
+	Model    string    `json:"model"`
+	Response string    `json:"response"`
+	Created  time.Time `json:"created"`
+	Used     time.Time `json:"used"`
+	Hits     int       `json:"hits"`
+}
+
+func NewResponseCache(path string, maxEntries, maxBytes int) *ResponseCache {
+	return &ResponseCache{
+		path:       path,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+}
+
+// CacheKey fingerprints a question: the normalised input, the hour bucket
+// (witching hour, night or day), whether Pale Luna is awake, the model and
+// the player's locale.
+func CacheKey(input string, context GameContext, model string) string {
+	parts := []string{
+		cacheVersion,
+		normalizeInput(input),
+		hourBucket(context.CurrentHour),
+		fmt.Sprint(context.PaleLunaAwake),
+		model,
+		context.Locale,
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func normalizeInput(input string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, input)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+func hourBucket(hour int) string {
+	switch {
+	case hour == 3:
+		return "witching"
+	case hour >= 0 && hour <= 5:
+		return "night"
+	default:
+		return "day"
+	}
+}
+
+func (c *ResponseCache) Path() string {
+	return c.path
+}
+
+func (c *ResponseCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	entry.Used = time.Now()
+	entry.Hits++
+	return entry.Response, true
+}
+
+func (c *ResponseCache) Put(key, input, model, response string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	now := time.Now()
+
+	if old, ok := c.entries[key]; ok {
+		c.size -= len(old.Response)
+	}
+	c.entries[key] = &CacheEntry{
+		Key:      key,
+		Input:    normalizeInput(input),
+		Model:    model,
+		Response: response,
+		Created:  now,
+		Used:     now,
+	}
+	c.size += len(response)
+
+	c.evict()
+	c.err = c.save()
+	return c.err
+}
+
+// Err is the error from the last attempt to save a reply, if it failed.
+func (c *ResponseCache) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Stats reports how many replies are cached and their total size in bytes.
+func (c *ResponseCache) Stats() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	return len(c.entries), c.size
+}
+
+func (c *ResponseCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*CacheEntry)
+	c.size = 0
+	c.loaded = true
+
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// load reads the cache file once. A missing or unreadable file starts an
+// empty cache; a damaged cache is not worth failing a turn over.
+func (c *ResponseCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]*CacheEntry)
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+
+	var entries []*CacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		c.entries[entry.Key] = entry
+		c.size += len(entry.Response)
+	}
+	c.evict()
+}
+
+func (c *ResponseCache) evict() {
+	if c.within() {
+		return
+	}
+
+	entries := c.sorted()
+	for _, entry := range entries {
+		if c.within() {
+			break
+		}
+		delete(c.entries, entry.Key)
+		c.size -= len(entry.Response)
+	}
+}
+
+func (c *ResponseCache) within() bool {
+	if c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		return false
+	}
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		return false
+	}
+	return true
+}
+
+// sorted lists entries least recently used first.
+func (c *ResponseCache) sorted() []*CacheEntry {
+	entries := make([]*CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Used.Before(entries[j].Used)
+	})
+	return entries
+}
+
+func (c *ResponseCache) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
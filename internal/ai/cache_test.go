@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int
+		ops        []string // "put key response" or "get key"
+		want       []string
+	}{
+		{
+			name:       "least recently used goes first",
+			maxEntries: 2,
+			ops:        []string{"put a 1", "put b 2", "put c 3"},
+			want:       []string{"b", "c"},
+		},
+		{
+			name:       "a hit keeps an entry",
+			maxEntries: 2,
+			ops:        []string{"put a 1", "put b 2", "get a", "put c 3"},
+			want:       []string{"a", "c"},
+		},
+		{
+			name:     "byte limit",
+			maxBytes: 8,
+			ops:      []string{"put a 1234", "put b 1234", "put c 12"},
+			want:     []string{"b", "c"},
+		},
+		{
+			name:     "replacing an entry frees its old size",
+			maxBytes: 8,
+			ops:      []string{"put a 12345678", "put a 1234", "put b 1234"},
+			want:     []string{"a", "b"},
+		},
+		{
+			name: "no limits",
+			ops:  []string{"put a 1", "put b 2", "put c 3"},
+			want: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		cache := NewResponseCache(filepath.Join(t.TempDir(), "cache.json"), tt.maxEntries, tt.maxBytes)
+
+		// Entries are stamped a second apart, so the order of use is not
+		// left to the resolution of the clock. The stamps lie in the past,
+		// so the entry being put is always the most recent while Put evicts.
+		past := time.Now().Add(-time.Hour)
+		for i, op := range tt.ops {
+			fields := strings.Fields(op)
+			switch fields[0] {
+			case "put":
+				if err := cache.Put(fields[1], fields[1], "model", fields[2]); err != nil {
+					t.Fatalf("%s: Put(%q) error = %v", tt.name, fields[1], err)
+				}
+			case "get":
+				if _, ok := cache.Get(fields[1]); !ok {
+					t.Fatalf("%s: Get(%q) missed", tt.name, fields[1])
+				}
+			}
+			if entry, ok := cache.entries[fields[1]]; ok {
+				entry.Used = past.Add(time.Duration(i) * time.Second)
+			}
+		}
+
+		var got []string
+		for key := range cache.entries {
+			got = append(got, key)
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Fatalf("%s: cached %v, want %v", tt.name, got, tt.want)
+		}
+
+		reloaded := NewResponseCache(cache.Path(), tt.maxEntries, tt.maxBytes)
+		if count, size := reloaded.Stats(); count != len(tt.want) || size != cache.size {
+			t.Fatalf("%s: reloaded Stats = %d, %d, want %d, %d", tt.name, count, size, len(tt.want), cache.size)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+const (
+	PolicyOff        = "off"
+	PolicyRegenerate = "regenerate"
+	PolicyTrim       = "trim"
+	PolicyFallback   = "fallback"
+)
+
+// Violation names a way a reply strayed from Pale Luna's voice or format.
+type Violation string
+
+const (
+	ViolationCharacterBreak Violation = "character_break"
+	ViolationMetaTalk       Violation = "meta_talk"
+	ViolationMarkdown       Violation = "markdown"
+	ViolationLength         Violation = "length"
+)
+
+const (
+	ActionRegenerated = "regenerated"
+	ActionTrimmed     = "trimmed"
+	ActionFallback    = "fallback"
+)
+
+// maxSentenceChars flags run-on sentences that dodge the sentence limit.
+const maxSentenceChars = 240
+
+// keptInterventions is how many recent interventions are remembered.
+const keptInterventions = 20
+
+// Phrases that give a reply away as out of character come from every
+// locale's catalog, since a model can slip into any language. They are
+// separated by "|" because some end in a comma ("as pale luna,").
+var (
+	phrasesOnce     sync.Once
+	characterBreaks []string
+	metaTalk        []string
+)
+
+func loadPhrases() {
+	for _, locale := range i18n.Supported() {
+		msg := i18n.For(locale)
+		characterBreaks = append(characterBreaks, phraseList(msg, "guard.character_breaks")...)
+		metaTalk = append(metaTalk, phraseList(msg, "guard.meta_talk")...)
+	}
+}
+
+func phraseList(msg *i18n.Catalog, key string) []string {
+	var phrases []string
+	for _, phrase := range strings.Split(msg.T(key), "|") {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return phrases
+}
+
+var markdownLine = regexp.MustCompile("^\\s*(#{1,6}\\s|[-*+]\\s|\\d+[.)](\\s|$)|```|>\\s)")
+
+// Intervention records one time the guardrails changed a reply, and why.
+type Intervention struct {
+	Time       time.Time
+	Input      string
+	Violations []Violation
+	Action     string
+	Original   string
+}
+
+// Guardrails validates replies after generation and, according to the
+// configured policy, regenerates, trims or replaces the ones that break
+// Pale Luna's persona rules.
+type Guardrails struct {
+	policy       string
+	maxSentences int
+	retries      int
+
+	mu            sync.Mutex
+	interventions []Intervention
+	total         int
+}
+
+func NewGuardrails(cfg *config.AIConfig) *Guardrails {
+	policy := strings.ToLower(strings.TrimSpace(cfg.Guardrails))
+	switch policy {
+	case PolicyOff, PolicyRegenerate, PolicyTrim, PolicyFallback:
+	case "":
+		policy = PolicyOff
+	default:
+		policy = PolicyRegenerate
+	}
+
+	maxSentences := cfg.MaxSentences
+	if maxSentences <= 0 {
+		maxSentences = 3
+	}
+
+	return &Guardrails{
+		policy:       policy,
+		maxSentences: maxSentences,
+		retries:      cfg.GuardRetries,
+	}
+}
+
+func (gr *Guardrails) Policy() string {
+	return gr.policy
+}
+
+func (gr *Guardrails) Enabled() bool {
+	return gr.policy != PolicyOff
+}
+
+// Check lists the rules response breaks, without duplicates.
+func (gr *Guardrails) Check(response string) []Violation {
+	var violations []Violation
+
+	sentences := splitSentences(response)
+	for _, sentence := range sentences {
+		violations = mergeViolations(violations, checkSentence(sentence))
+	}
+	if len(sentences) > gr.maxSentences {
+		violations = mergeViolations(violations, []Violation{ViolationLength})
+	}
+
+	return violations
+}
+
+// Review returns response if it passes, or whatever the policy makes of it.
+// regenerate asks the backend for a fresh reply. ErrRejected means the
+// policy wants the reply replaced with a fallback. What was done is noted
+// on trace.
+func (gr *Guardrails) Review(input, response string, trace *Trace, regenerate func() (string, error)) (string, error) {
+	if !gr.Enabled() {
+		return response, nil
+	}
+
+	violations := gr.Check(response)
+	if len(violations) == 0 {
+		return response, nil
+	}
+
+	return gr.resolve(input, response, violations, trace, regenerate)
+}
+
+func (gr *Guardrails) resolve(input, response string, violations []Violation, trace *Trace, regenerate func() (string, error)) (string, error) {
+	if gr.policy == PolicyRegenerate {
+		for i := 0; i < gr.retries; i++ {
+			candidate, err := regenerate()
+			if err == nil && candidate != "" && len(gr.Check(candidate)) == 0 {
+				gr.record(input, violations, ActionRegenerated, response, trace)
+				return candidate, nil
+			}
+		}
+	}
+
+	if gr.policy != PolicyFallback {
+		if trimmed := gr.Trim(response); trimmed != "" {
+			gr.record(input, violations, ActionTrimmed, response, trace)
+			return trimmed, nil
+		}
+	}
+
+	gr.record(input, violations, ActionFallback, response, trace)
+	return "", ErrRejected
+}
+
+// Trim keeps the first sentences of response that are in character, with
+// markdown markers removed, up to the sentence limit.
+func (gr *Guardrails) Trim(response string) string {
+	var lines []string
+	for _, line := range strings.Split(response, "\n") {
+		line = markdownLine.ReplaceAllString(line, "")
+		line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// List items rarely end their own sentences.
+		if !strings.ContainsAny(line[len(line)-1:], ".!?:\"") {
+			line += "."
+		}
+		lines = append(lines, line)
+	}
+
+	var kept []string
+	for _, sentence := range splitSentences(strings.Join(lines, " ")) {
+		if len(kept) == gr.maxSentences {
+			break
+		}
+		if len(checkSentence(sentence)) > 0 {
+			continue
+		}
+		kept = append(kept, strings.TrimSpace(sentence))
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// Interventions returns the most recent interventions, oldest first, and
+// how many there have been in total.
+func (gr *Guardrails) Interventions() ([]Intervention, int) {
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	recent := make([]Intervention, len(gr.interventions))
+	copy(recent, gr.interventions)
+	return recent, gr.total
+}
+
+func (gr *Guardrails) record(input string, violations []Violation, action, original string, trace *Trace) {
+	trace.intervened(action)
+
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	gr.total++
+	gr.interventions = append(gr.interventions, Intervention{
+		Time:       time.Now(),
+		Input:      input,
+		Violations: violations,
+		Action:     action,
+		Original:   original,
+	})
+	if len(gr.interventions) > keptInterventions {
+		gr.interventions = gr.interventions[len(gr.interventions)-keptInterventions:]
+	}
+}
+
+func checkSentence(sentence string) []Violation {
+	var violations []Violation
+	lower := strings.ToLower(sentence)
+	phrasesOnce.Do(loadPhrases)
+
+	if containsAny(lower, characterBreaks) {
+		violations = append(violations, ViolationCharacterBreak)
+	}
+	if containsAny(lower, metaTalk) {
+		violations = append(violations, ViolationMetaTalk)
+	}
+	if hasMarkdown(sentence) {
+		violations = append(violations, ViolationMarkdown)
+	}
+	if len(strings.TrimSpace(sentence)) > maxSentenceChars {
+		violations = append(violations, ViolationLength)
+	}
+
+	return violations
+}
+
+func hasMarkdown(text string) bool {
+	if strings.Contains(text, "**") || strings.Contains(text, "__") || strings.Contains(text, "`") {
+		return true
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if markdownLine.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeViolations(into, from []Violation) []Violation {
+	for _, v := range from {
+		seen := false
+		for _, existing := range into {
+			if existing == v {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			into = append(into, v)
+		}
+	}
+	return into
+}
+
+// splitSentences breaks text after ., ! or ? (and any closing quotes) when
+// whitespace follows. Ellipses do not end a sentence, since Pale Luna trails
+// off mid-thought.
+func splitSentences(text string) []string {
+	var sentences []string
+	for {
+		sentence, rest, ok := nextSentence(text)
+		if !ok {
+			break
+		}
+		sentences = append(sentences, sentence)
+		text = rest
+	}
+
+	if strings.TrimSpace(text) != "" {
+		sentences = append(sentences, text)
+	}
+	return sentences
+}
+
+// nextSentence returns the first complete sentence in text and what follows
+// it. ok is false until the sentence's end has been seen.
+func nextSentence(text string) (sentence, rest string, ok bool) {
+	for i := 0; i < len(text); i++ {
+		if !strings.ContainsRune(".!?", rune(text[i])) {
+			continue
+		}
+
+		end := i
+		for end < len(text) && strings.ContainsRune(".!?", rune(text[end])) {
+			end++
+		}
+		if strings.HasPrefix(text[i:end], "..") {
+			i = end - 1
+			continue
+		}
+		for end < len(text) && strings.ContainsRune("\"')]", rune(text[end])) {
+			end++
+		}
+		if strings.HasPrefix(text[end:], "”") || strings.HasPrefix(text[end:], "’") {
+			end += len("”")
+		}
+
+		if end < len(text) && strings.ContainsRune(" \t\r\n", rune(text[end])) {
+			if strings.TrimSpace(text[:end]) == "" {
+				i = end - 1
+				continue
+			}
+			return text[:end], text[end:], true
+		}
+		i = end - 1
+	}
+	return "", text, false
+}
+
+// streamGate passes a streamed reply through sentence by sentence, holding
+// each back until it has been checked. The first sentence that breaks a
+// rule, or one past the limit, stops the stream there.
+type streamGate struct {
+	guardrails *Guardrails
+	onChunk    func(string)
+	pending    string
+	shown      strings.Builder
+	sentences  int
+	violations []Violation
+	stopped    bool
+}
+
+func (gr *Guardrails) gate(onChunk func(string)) *streamGate {
+	return &streamGate{guardrails: gr, onChunk: onChunk}
+}
+
+func (sg *streamGate) Write(chunk string) {
+	if sg.stopped {
+		return
+	}
+
+	sg.pending += chunk
+	for !sg.stopped {
+		sentence, rest, ok := nextSentence(sg.pending)
+		if !ok {
+			return
+		}
+		sg.pending = rest
+		sg.admit(sentence)
+	}
+}
+
+// Close lets through what is left and returns what the player saw and the
+// rules that were broken.
+func (sg *streamGate) Close() (string, []Violation) {
+	if !sg.stopped && strings.TrimSpace(sg.pending) != "" {
+		sg.admit(sg.pending)
+	}
+	sg.pending = ""
+
+	return strings.TrimSpace(sg.shown.String()), sg.violations
+}
+
+func (sg *streamGate) admit(sentence string) {
+	violations := checkSentence(sentence)
+	if sg.sentences >= sg.guardrails.maxSentences {
+		violations = mergeViolations(violations, []Violation{ViolationLength})
+	}
+
+	if len(violations) > 0 {
+		sg.violations = mergeViolations(sg.violations, violations)
+		sg.stopped = true
+		return
+	}
+
+	sg.sentences++
+	sg.shown.WriteString(sentence)
+	sg.onChunk(sentence)
+}
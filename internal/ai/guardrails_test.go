@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Dig.", []string{"Dig."}},
+		{"The earth remembers. Dig!", []string{"The earth remembers.", " Dig!"}},
+		{"Why? Why now?!\nGo.", []string{"Why?", " Why now?!", "\nGo."}},
+		{"The earth... remembers. Dig.", []string{"The earth... remembers.", " Dig."}},
+		{"She said \"go.\" Then silence.", []string{"She said \"go.\"", " Then silence."}},
+		{"She said “go.” Then silence.", []string{"She said “go.”", " Then silence."}},
+		{"Version 3.2 is here", []string{"Version 3.2 is here"}},
+		{"... Hello.", []string{"... Hello."}},
+		{"Dig. \n", []string{"Dig."}},
+	}
+
+	for _, tt := range tests {
+		if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStreamGate(t *testing.T) {
+	tests := []struct {
+		name       string
+		reply      string
+		shown      string
+		violations []Violation
+	}{
+		{
+			name:  "clean reply passes",
+			reply: "The earth... remembers. Dig.",
+			shown: "The earth... remembers. Dig.",
+		},
+		{
+			name:       "stops before a character break",
+			reply:      "I see you. As an AI language model, I cannot. Dig.",
+			shown:      "I see you.",
+			violations: []Violation{ViolationCharacterBreak},
+		},
+		{
+			name:       "stops at the sentence limit",
+			reply:      "One. Two. Three.",
+			shown:      "One. Two.",
+			violations: []Violation{ViolationLength},
+		},
+		{
+			name:       "stops before markdown",
+			reply:      "Listen. **Dig** now.",
+			shown:      "Listen.",
+			violations: []Violation{ViolationMarkdown},
+		},
+		{
+			name:  "unfinished last sentence is let through on close",
+			reply: "Dig. The gold waits",
+			shown: "Dig. The gold waits",
+		},
+	}
+
+	for _, tt := range tests {
+		gr := NewGuardrails(&config.AIConfig{Guardrails: PolicyRegenerate, MaxSentences: 2})
+
+		var streamed string
+		gate := gr.gate(func(chunk string) { streamed += chunk })
+		// Feed the reply a few bytes at a time, as a backend streams it.
+		for i := 0; i < len(tt.reply); i += 3 {
+			gate.Write(tt.reply[i:min(i+3, len(tt.reply))])
+		}
+		shown, violations := gate.Close()
+
+		if shown != tt.shown || !reflect.DeepEqual(violations, tt.violations) {
+			t.Fatalf("%s: Close = %q, %v, want %q, %v", tt.name, shown, violations, tt.shown, tt.violations)
+		}
+		if strings.TrimSpace(streamed) != shown {
+			t.Fatalf("%s: streamed %q, but Close reported %q", tt.name, streamed, shown)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package ai
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	tokenBuckets   = []float64{10, 25, 50, 100, 150, 250, 500}
+	speedBuckets   = []float64{1, 2.5, 5, 10, 20, 40, 80}
+)
+
+// Metrics counts where replies came from, why the model could not give
+// them, what the guardrails did, and how long and how many tokens the
+// model took. Each game session keeps its own; the AgentManager keeps one
+// for the whole process.
+type Metrics struct {
+	mu            sync.Mutex
+	started       time.Time
+	replies       map[string]int
+	fallbacks     map[string]int
+	interventions map[string]int
+	latency       *histogram
+	tokens        *histogram
+	speed         *histogram
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		started:       time.Now(),
+		replies:       make(map[string]int),
+		fallbacks:     make(map[string]int),
+		interventions: make(map[string]int),
+		latency:       newHistogram(latencyBuckets),
+		tokens:        newHistogram(tokenBuckets),
+		speed:         newHistogram(speedBuckets),
+	}
+}
+
+// Observe counts a turn. A reply that carries an error is a fallback, counted
+// under FailureReason; latency is only measured when the model was asked.
+func (m *Metrics) Observe(reply Reply) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.replies[reply.Source]++
+	if reply.Err != nil {
+		m.fallbacks[FailureReason(reply.Err)]++
+	}
+
+	trace := reply.Trace
+	if trace == nil || trace.Requests == 0 {
+		return
+	}
+
+	m.latency.observe(reply.Duration.Seconds())
+	if trace.Guardrail != "" {
+		m.interventions[trace.Guardrail]++
+	}
+	if trace.Tokens > 0 {
+		m.tokens.observe(float64(trace.Tokens))
+		if trace.EvalDuration > 0 {
+			m.speed.observe(float64(trace.Tokens) / trace.EvalDuration.Seconds())
+		}
+	}
+}
+
+// MetricsSummary is a copy of the metrics at one moment.
+type MetricsSummary struct {
+	Since           time.Time
+	Replies         int
+	Sources         map[string]int
+	Fallbacks       map[string]int
+	Interventions   map[string]int
+	Latency         Distribution
+	Tokens          Distribution
+	TokensPerSecond Distribution
+}
+
+// FallbackRate is the share of replies the model could not give.
+func (s MetricsSummary) FallbackRate() float64 {
+	if s.Replies == 0 {
+		return 0
+	}
+	return float64(Total(s.Fallbacks)) / float64(s.Replies)
+}
+
+// Distribution summarises a histogram. The quantiles are estimated from the
+// buckets.
+type Distribution struct {
+	Count int
+	Sum   float64
+	Mean  float64
+	P50   float64
+	P95   float64
+	Max   float64
+}
+
+func (m *Metrics) Summary() MetricsSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return MetricsSummary{
+		Since:           m.started,
+		Replies:         Total(m.replies),
+		Sources:         copyCounts(m.replies),
+		Fallbacks:       copyCounts(m.fallbacks),
+		Interventions:   copyCounts(m.interventions),
+		Latency:         m.latency.summary(),
+		Tokens:          m.tokens.summary(),
+		TokensPerSecond: m.speed.summary(),
+	}
+}
+
+// Total adds up a set of counts.
+func Total(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// FormatCounts lists counts largest first, as "ai 12, fallback 3".
+func FormatCounts(counts map[string]int) string {
+	names := sortedKeys(counts)
+	sort.SliceStable(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+
+	text := ""
+	for i, name := range names {
+		if i > 0 {
+			text += ", "
+		}
+		text += fmt.Sprintf("%s %d", name, counts[name])
+	}
+	return text
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounter(w, "pale_luna_ai_replies_total", "Replies by where they came from.", "source", m.replies)
+	writeCounter(w, "pale_luna_ai_fallbacks_total", "Replies the model could not give, by reason.", "reason", m.fallbacks)
+	writeCounter(w, "pale_luna_ai_guardrail_interventions_total", "Replies the guardrails changed, by action.", "action", m.interventions)
+	m.latency.write(w, "pale_luna_ai_request_duration_seconds", "Time to answer a turn that asked the model.")
+	m.tokens.write(w, "pale_luna_ai_generated_tokens", "Tokens generated per turn.")
+	m.speed.write(w, "pale_luna_ai_tokens_per_second", "Generation speed per turn.")
+}
+
+// ServeHTTP serves the metrics to a Prometheus scraper.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+func writeCounter(w io.Writer, name, help, label string, counts map[string]int) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(counts) {
+		fmt.Fprintf(w, "%s{%s=%q} %d\n", name, label, key, counts[key])
+	}
+}
+
+// histogram counts observations into buckets with the given upper bounds,
+// plus one for everything above the last.
+type histogram struct {
+	bounds []float64
+	counts []int
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.counts[i]++
+	h.count++
+	h.sum += v
+	if h.count == 1 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+}
+
+func (h *histogram) summary() Distribution {
+	if h.count == 0 {
+		return Distribution{}
+	}
+	return Distribution{
+		Count: h.count,
+		Sum:   h.sum,
+		Mean:  h.sum / float64(h.count),
+		P50:   h.quantile(0.5),
+		P95:   h.quantile(0.95),
+		Max:   h.max,
+	}
+}
+
+// quantile interpolates within the bucket the q-th observation falls in,
+// narrowed to the smallest and largest observations.
+func (h *histogram) quantile(q float64) float64 {
+	rank := q * float64(h.count)
+	seen := 0.0
+	lower := h.min
+
+	for i, n := range h.counts {
+		upper := h.max
+		if i < len(h.bounds) {
+			upper = math.Min(h.bounds[i], h.max)
+		}
+		if n > 0 && seen+float64(n) >= rank {
+			return lower + (upper-lower)*(rank-seen)/float64(n)
+		}
+		seen += float64(n)
+		lower = math.Max(upper, h.min)
+	}
+	return h.max
+}
+
+func (h *histogram) write(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	cumulative := 0
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for key, n := range counts {
+		copied[key] = n
+	}
+	return copied
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNoModelList      = errors.New("only the ollama backend can list and inspect models")
+	ErrModelNotSwitched = errors.New("this backend's model cannot be switched")
+)
+
+// ModelSwitcher is implemented by agents whose model can be changed while
+// the game runs.
+type ModelSwitcher interface {
+	Model() string
+	SetModel(name string)
+}
+
+// ModelNotInstalledError reports a model the Ollama server does not have.
+type ModelNotInstalledError struct {
+	Name string
+}
+
+func (e *ModelNotInstalledError) Error() string {
+	return fmt.Sprintf("model %s is not installed", e.Name)
+}
+
+// ModelShow is what Ollama's /api/show reports about a model.
+type ModelShow struct {
+	Parameters string                 `json:"parameters"`
+	Template   string                 `json:"template"`
+	System     string                 `json:"system"`
+	License    string                 `json:"license"`
+	Details    ModelDetails           `json:"details"`
+	ModelInfo  map[string]interface{} `json:"model_info"`
+}
+
+// ContextLength returns the model's context window, if the server says.
+func (ms *ModelShow) ContextLength() int {
+	key := ms.Details.Family + ".context_length"
+	if length, ok := ms.ModelInfo[key].(float64); ok {
+		return int(length)
+	}
+	return 0
+}
+
+// ShowModel asks the Ollama server for a model's details, parameters and
+// prompt template.
+func (oc *OllamaClient) ShowModel(name string) (*ModelShow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.config.OllamaURL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &ModelNotInstalledError{Name: name}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode}
+	}
+
+	var show ModelShow
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &show, nil
+}
+
+// Model is the model Pale Luna currently speaks through.
+func (am *AgentManager) Model() string {
+	if switcher, ok := am.agent.(ModelSwitcher); ok {
+		return switcher.Model()
+	}
+	return am.config.AI.Model
+}
+
+// ListModels returns the models installed on the Ollama server.
+func (am *AgentManager) ListModels() ([]ModelInfo, error) {
+	ollama, ok := am.agent.(*OllamaClient)
+	if !ok {
+		return nil, ErrNoModelList
+	}
+	return ollama.ListModels()
+}
+
+// ShowModel describes name, or the current model if name is empty.
+func (am *AgentManager) ShowModel(name string) (*ModelShow, error) {
+	ollama, ok := am.agent.(*OllamaClient)
+	if !ok {
+		return nil, ErrNoModelList
+	}
+	if name == "" {
+		name = ollama.Model()
+	}
+	return ollama.ShowModel(name)
+}
+
+// UseModel switches the running backend to name. On Ollama the model must
+// already be installed. Every session sharing the manager switches too.
+func (am *AgentManager) UseModel(name string) error {
+	switcher, ok := am.agent.(ModelSwitcher)
+	if !ok {
+		return ErrModelNotSwitched
+	}
+
+	if _, ok := am.agent.(*OllamaClient); ok {
+		models, err := am.ListModels()
+		if err != nil {
+			return err
+		}
+		if !HasModel(models, name) {
+			return &ModelNotInstalledError{Name: name}
+		}
+	}
+
+	switcher.SetModel(name)
+	return nil
+}
+
+// MissingModel returns the current model's name if the Ollama server is
+// reachable and does not have it, or "" otherwise.
+func (am *AgentManager) MissingModel() string {
+	models, err := am.ListModels()
+	if err != nil {
+		return ""
+	}
+
+	if model := am.Model(); !HasModel(models, model) {
+		return model
+	}
+	return ""
+}
+
+// FormatSize renders a byte count the way model sizes are usually given.
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// PullProgress is one line of Ollama's streamed /api/pull reply. Layers
+// being downloaded carry a digest and byte counts.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PullModel downloads name onto the Ollama server, reporting progress as it
+// goes, until it finishes or ctx is cancelled. Ollama keeps the layers it
+// has already fetched, so pulling again after a cancellation resumes.
+func (oc *OllamaClient) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.config.OllamaURL+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// A pull takes as long as the download does; only ctx ends it.
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var progress PullProgress
+		if err := decoder.Decode(&progress); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if resp.StatusCode != http.StatusOK {
+				return &StatusError{Code: resp.StatusCode}
+			}
+			if err == io.EOF {
+				return errors.New("pull ended before it finished")
+			}
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if progress.Error != "" {
+			return fmt.Errorf("pull failed: %s", progress.Error)
+		}
+
+		onProgress(progress)
+		if progress.Status == "success" {
+			return nil
+		}
+	}
+}
+
+// PullModel downloads name onto the Ollama server. See OllamaClient.PullModel.
+func (am *AgentManager) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	ollama, ok := am.agent.(*OllamaClient)
+	if !ok {
+		return ErrNoModelList
+	}
+	return ollama.PullModel(ctx, name, onProgress)
+}
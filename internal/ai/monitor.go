@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Health is what the monitor knows about the AI backend.
+type Health struct {
+	Online  bool
+	Since   time.Time
+	Checked time.Time
+	Err     error
+}
+
+// Monitor health-checks the AI backend in the background, so no turn waits
+// on a probe, and tells listeners when Pale Luna's connection drops or comes
+// back. The backend counts as online while it answers health checks and the
+// breaker is closed; failed checks keep the breaker open.
+type Monitor struct {
+	probe    func() bool
+	breaker  *Breaker
+	interval time.Duration
+
+	start    sync.Once
+	stopOnce sync.Once
+	stop     chan struct{}
+
+	mu        sync.Mutex
+	up        bool
+	online    bool
+	since     time.Time
+	checked   time.Time
+	listeners map[int]func(online bool)
+	nextID    int
+}
+
+func NewMonitor(probe func() bool, breaker *Breaker, interval time.Duration) *Monitor {
+	return &Monitor{
+		probe:     probe,
+		breaker:   breaker,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		listeners: make(map[int]func(bool)),
+	}
+}
+
+// Start checks the backend once and then keeps checking every interval. It
+// only does anything the first time it is called, and not at all once the
+// monitor has been stopped.
+func (m *Monitor) Start() {
+	m.start.Do(func() {
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		m.Check()
+		if m.interval > 0 {
+			go m.run()
+		}
+	})
+}
+
+// Stop ends background checking. It never probes the backend itself.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+func (m *Monitor) run() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check probes the backend now. A failed probe opens the breaker; a good one
+// closes it again if it was a failed probe that opened it.
+func (m *Monitor) Check() {
+	up := m.probe()
+	if up {
+		if _, err := m.breaker.State(); errors.Is(err, ErrUnavailable) {
+			m.breaker.Record(nil)
+		}
+	} else {
+		m.breaker.Trip(ErrUnavailable)
+	}
+
+	m.mu.Lock()
+	m.up = up
+	m.checked = time.Now()
+	m.mu.Unlock()
+
+	m.update()
+}
+
+// Observe learns from a request that went through: an answer of any kind
+// means the backend is up, whatever the last health check said.
+func (m *Monitor) Observe(err error) {
+	if !isBackendFailure(err) {
+		m.mu.Lock()
+		m.up = true
+		m.mu.Unlock()
+	}
+	m.update()
+}
+
+func (m *Monitor) update() {
+	state, _ := m.breaker.State()
+
+	m.mu.Lock()
+	online := m.up && state == BreakerClosed
+	first := m.since.IsZero()
+	changed := first || online != m.online
+	if changed {
+		m.online = online
+		m.since = time.Now()
+	}
+
+	var listeners []func(bool)
+	if changed && !first {
+		for _, listener := range m.listeners {
+			listeners = append(listeners, listener)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(online)
+	}
+}
+
+func (m *Monitor) Health() Health {
+	_, err := m.breaker.State()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return Health{
+		Online:  m.online,
+		Since:   m.since,
+		Checked: m.checked,
+		Err:     err,
+	}
+}
+
+// Subscribe calls listener whenever the backend goes offline or comes back.
+// It is called from whichever goroutine noticed the change, which may be a
+// player's request, so it must not block. The returned function
+// unsubscribes.
+func (m *Monitor) Subscribe(listener func(online bool)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.nextID
+	m.nextID++
+	m.listeners[id] = listener
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.listeners, id)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
@@ -16,19 +18,59 @@ type OllamaClient struct {
 	config     *config.AIConfig
 	httpClient *http.Client
 	prompts    *PromptBuilder
+
+	mu    sync.RWMutex
+	model string
 }
 
+const (
+	APIChat     = "chat"
+	APIGenerate = "generate"
+)
+
 type OllamaRequest struct {
-	Model   string                 `json:"model"`
-	Prompt  string                 `json:"prompt"`
-	Stream  bool                   `json:"stream"`
-	Options map[string]interface{} `json:"options,omitempty"`
+	Model    string                 `json:"model"`
+	Prompt   string                 `json:"prompt,omitempty"`
+	Messages []ChatMessage          `json:"messages,omitempty"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
 }
 
+// OllamaResponse covers both /api/generate (Response) and /api/chat
+// (Message) replies, streamed or not.
 type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-	Error    string `json:"error,omitempty"`
+	Response string       `json:"response"`
+	Message  *ChatMessage `json:"message,omitempty"`
+	Done     bool         `json:"done"`
+	Error    string       `json:"error,omitempty"`
+
+	EvalCount       int   `json:"eval_count,omitempty"`
+	EvalDuration    int64 `json:"eval_duration,omitempty"`
+	PromptEvalCount int   `json:"prompt_eval_count,omitempty"`
+}
+
+func (r OllamaResponse) record(trace *Trace) {
+	trace.usage(r.EvalCount, r.PromptEvalCount, time.Duration(r.EvalDuration))
+}
+
+func (r OllamaResponse) Text() string {
+	if r.Message != nil {
+		return r.Message.Content
+	}
+	return r.Response
+}
+
+type ModelDetails struct {
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+type ModelInfo struct {
+	Name       string       `json:"name"`
+	Size       int64        `json:"size"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Details    ModelDetails `json:"details"`
 }
 
 func NewOllamaClient(cfg *config.AIConfig) *OllamaClient {
@@ -37,10 +79,25 @@ func NewOllamaClient(cfg *config.AIConfig) *OllamaClient {
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
-		prompts: NewPromptBuilder(),
+		prompts: promptsFrom(cfg.PromptDir),
+		model:   cfg.Model,
 	}
 }
 
+// Model is the model replies are asked of, which starts as the configured
+// one and may be switched while the game runs.
+func (oc *OllamaClient) Model() string {
+	oc.mu.RLock()
+	defer oc.mu.RUnlock()
+	return oc.model
+}
+
+func (oc *OllamaClient) SetModel(name string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.model = name
+}
+
 func (oc *OllamaClient) IsAvailable() bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -59,102 +116,198 @@ func (oc *OllamaClient) IsAvailable() bool {
 	return resp.StatusCode == http.StatusOK
 }
 
-func (oc *OllamaClient) ProcessCommand(input string, gameContext GameContext) (string, error) {
-	if !oc.config.Enabled {
-		return GetFallbackResponse(input, gameContext), nil
+// ListModels returns the models installed on the Ollama server.
+func (oc *OllamaClient) ListModels() ([]ModelInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", oc.config.OllamaURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	prompt := oc.prompts.BuildPrompt(input, gameContext)
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
 
-	reqBody := OllamaRequest{
-		Model:  oc.config.Model,
-		Prompt: prompt,
-		Stream: false,
-		Options: map[string]interface{}{
-			"temperature": oc.config.Temperature,
-			"num_predict": oc.config.MaxTokens,
-		},
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode}
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
+	var tags struct {
+		Models []ModelInfo `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return tags.Models, nil
+}
+
+// HasModel reports whether name is installed, treating a missing tag as
+// ":latest" the way Ollama does.
+func HasModel(models []ModelInfo, name string) bool {
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	for _, model := range models {
+		if model.Name == name {
+			return true
 		}
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return false
+}
+
+func (oc *OllamaClient) ProcessCommand(input string, gameContext GameContext) (string, error) {
+	if !oc.config.Enabled {
+		return "", ErrAIDisabled
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), oc.config.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", oc.config.OllamaURL+"/api/generate", bytes.NewBuffer(jsonData))
+	req, err := oc.newRequest(ctx, input, gameContext, false)
 	if err != nil {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
-		}
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := oc.httpClient.Do(req)
 	if err != nil {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
-		}
 		return "", fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
-		}
-		return "", fmt.Errorf("API returned status %d", resp.StatusCode)
+		return "", &StatusError{Code: resp.StatusCode}
 	}
 
 	var ollamaResp OllamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
-		}
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if ollamaResp.Error != "" {
-		if oc.config.FallbackEnabled {
-			return GetFallbackResponse(input, gameContext), nil
-		}
 		return "", fmt.Errorf("API error: %s", ollamaResp.Error)
 	}
 
-	response := cleanAIResponse(ollamaResp.Response)
+	response := cleanAIResponse(ollamaResp.Text())
+	gameContext.Trace.reply(ollamaResp.Text(), response)
+	ollamaResp.record(gameContext.Trace)
 	if response == "" {
-		return GetFallbackResponse(input, gameContext), nil
+		return "", ErrEmptyReply
 	}
 
 	return response, nil
 }
 
-func cleanAIResponse(response string) string {
-	response = removePrefix(response, "Pale Luna:")
-	response = removePrefix(response, "Response:")
-	response = removePrefix(response, "*")
-	response = removeSuffix(response, "*")
+// ProcessCommandStream asks Ollama for a streamed reply and hands cleaned
+// text to onChunk as it arrives. Once any text has been emitted a broken
+// stream is treated as the end of the reply rather than an error.
+func (oc *OllamaClient) ProcessCommandStream(input string, gameContext GameContext, onChunk func(string)) (string, error) {
+	if !oc.config.Enabled {
+		return "", ErrAIDisabled
+	}
 
-	return strings.TrimSpace(response)
-}
+	ctx, cancel := context.WithTimeout(context.Background(), oc.config.Timeout)
+	defer cancel()
+
+	req, err := oc.newRequest(ctx, input, gameContext, true)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{Code: resp.StatusCode}
+	}
+
+	var raw strings.Builder
+	cleaner := &streamCleaner{}
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if err != io.EOF && raw.Len() == 0 {
+				return "", fmt.Errorf("failed to decode response: %w", err)
+			}
+			break
+		}
+
+		if chunk.Error != "" {
+			if raw.Len() == 0 {
+				return "", fmt.Errorf("API error: %s", chunk.Error)
+			}
+			break
+		}
+
+		raw.WriteString(chunk.Text())
+		if text := cleaner.Write(chunk.Text()); text != "" {
+			onChunk(text)
+		}
+
+		if chunk.Done {
+			chunk.record(gameContext.Trace)
+			break
+		}
+	}
+
+	if text := cleaner.Flush(); text != "" {
+		onChunk(text)
+	}
 
-func removePrefix(s, prefix string) string {
-	if strings.HasPrefix(s, prefix) {
-		return strings.TrimSpace(s[len(prefix):])
+	response := cleanAIResponse(raw.String())
+	gameContext.Trace.reply(raw.String(), response)
+	if response == "" {
+		return "", ErrEmptyReply
 	}
-	return s
+
+	return response, nil
 }
 
-func removeSuffix(s, suffix string) string {
-	if strings.HasSuffix(s, suffix) {
-		return strings.TrimSpace(s[:len(s)-len(suffix)])
+func (oc *OllamaClient) newRequest(ctx context.Context, input string, gameContext GameContext, stream bool) (*http.Request, error) {
+	reqBody := OllamaRequest{
+		Model:  oc.Model(),
+		Stream: stream,
+		Options: map[string]interface{}{
+			"temperature": oc.config.Temperature,
+			"num_predict": oc.config.MaxTokens,
+		},
+	}
+	if oc.config.TopP > 0 {
+		reqBody.Options["top_p"] = oc.config.TopP
+	}
+
+	endpoint := "/api/chat"
+	if oc.config.API == APIGenerate {
+		endpoint = "/api/generate"
+		reqBody.Prompt = oc.prompts.BuildPrompt(input, gameContext)
+	} else {
+		reqBody.Messages = oc.prompts.BuildMessages(input, gameContext)
 	}
-	return s
+	gameContext.Trace.request(reqBody.Prompt, reqBody.Messages)
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.config.OllamaURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func cleanAIResponse(response string) string {
+	return cleanTrailing(cleanLeading(response))
 }
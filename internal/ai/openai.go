@@ -0,0 +1,280 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+)
+
+// OpenAIClient talks to any server exposing the OpenAI chat completions API,
+// such as llama.cpp server, LM Studio or vLLM.
+type OpenAIClient struct {
+	config     *config.AIConfig
+	httpClient *http.Client
+	prompts    *PromptBuilder
+
+	mu    sync.RWMutex
+	model string
+}
+
+type OpenAIRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Stream      bool          `json:"stream"`
+	Temperature float32       `json:"temperature"`
+	TopP        float32       `json:"top_p,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+}
+
+type OpenAIResponse struct {
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
+	Error   *OpenAIError   `json:"error,omitempty"`
+}
+
+// OpenAIUsage counts tokens. The API gives no generation time, so the
+// whole request stands in for it when working out tokens per second.
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *OpenAIUsage) record(trace *Trace, elapsed time.Duration) {
+	if u != nil {
+		trace.usage(u.CompletionTokens, u.PromptTokens, elapsed)
+	}
+}
+
+type OpenAIChoice struct {
+	Message      ChatMessage `json:"message"`
+	Delta        ChatMessage `json:"delta"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type OpenAIError struct {
+	Message string `json:"message"`
+}
+
+func NewOpenAIClient(cfg *config.AIConfig) *OpenAIClient {
+	return &OpenAIClient{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		prompts: promptsFrom(cfg.PromptDir),
+		model:   cfg.Model,
+	}
+}
+
+// Model is the model replies are asked of, which starts as the configured
+// one and may be switched while the game runs.
+func (oc *OpenAIClient) Model() string {
+	oc.mu.RLock()
+	defer oc.mu.RUnlock()
+	return oc.model
+}
+
+func (oc *OpenAIClient) SetModel(name string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.model = name
+}
+
+func (oc *OpenAIClient) IsAvailable() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", oc.endpoint("/models"), nil)
+	if err != nil {
+		return false
+	}
+	oc.authorize(req)
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (oc *OpenAIClient) ProcessCommand(input string, gameContext GameContext) (string, error) {
+	if !oc.config.Enabled {
+		return "", ErrAIDisabled
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), oc.config.Timeout)
+	defer cancel()
+
+	req, err := oc.newRequest(ctx, input, gameContext, false)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{Code: resp.StatusCode}
+	}
+
+	var completion OpenAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if completion.Error != nil {
+		return "", fmt.Errorf("API error: %s", completion.Error.Message)
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", ErrEmptyReply
+	}
+
+	response := cleanAIResponse(completion.Choices[0].Message.Content)
+	gameContext.Trace.reply(completion.Choices[0].Message.Content, response)
+	completion.Usage.record(gameContext.Trace, time.Since(start))
+	if response == "" {
+		return "", ErrEmptyReply
+	}
+
+	return response, nil
+}
+
+// ProcessCommandStream reads the server-sent events of a streamed completion
+// and hands cleaned text to onChunk as it arrives.
+func (oc *OpenAIClient) ProcessCommandStream(input string, gameContext GameContext, onChunk func(string)) (string, error) {
+	if !oc.config.Enabled {
+		return "", ErrAIDisabled
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), oc.config.Timeout)
+	defer cancel()
+
+	req, err := oc.newRequest(ctx, input, gameContext, true)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{Code: resp.StatusCode}
+	}
+
+	// Time spent in onChunk is the caller's, not the model's, so it is left
+	// out of the generation time.
+	var raw strings.Builder
+	var drawing time.Duration
+	cleaner := &streamCleaner{}
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk OpenAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil || chunk.Error != nil {
+			continue
+		}
+		chunk.Usage.record(gameContext.Trace, time.Since(start)-drawing)
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		raw.WriteString(text)
+		if cleaned := cleaner.Write(text); cleaned != "" {
+			drawn := time.Now()
+			onChunk(cleaned)
+			drawing += time.Since(drawn)
+		}
+	}
+
+	if raw.Len() == 0 && scanner.Err() != nil {
+		return "", fmt.Errorf("failed to read stream: %w", scanner.Err())
+	}
+
+	if text := cleaner.Flush(); text != "" {
+		onChunk(text)
+	}
+
+	response := cleanAIResponse(raw.String())
+	gameContext.Trace.reply(raw.String(), response)
+	if response == "" {
+		return "", ErrEmptyReply
+	}
+
+	return response, nil
+}
+
+func (oc *OpenAIClient) newRequest(ctx context.Context, input string, gameContext GameContext, stream bool) (*http.Request, error) {
+	reqBody := OpenAIRequest{
+		Model:       oc.Model(),
+		Messages:    oc.prompts.BuildMessages(input, gameContext),
+		Stream:      stream,
+		Temperature: oc.config.Temperature,
+		TopP:        oc.config.TopP,
+		MaxTokens:   oc.config.MaxTokens,
+	}
+	gameContext.Trace.request("", reqBody.Messages)
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", oc.endpoint("/chat/completions"), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	oc.authorize(req)
+	return req, nil
+}
+
+func (oc *OpenAIClient) endpoint(path string) string {
+	return strings.TrimRight(oc.config.OpenAI.BaseURL, "/") + path
+}
+
+// authorize sets the API key header. The standard Authorization header gets
+// the Bearer scheme; custom headers (e.g. "api-key") carry the bare key.
+func (oc *OpenAIClient) authorize(req *http.Request) {
+	key := oc.config.OpenAI.APIKey
+	if key == "" {
+		return
+	}
+
+	header := oc.config.OpenAI.APIKeyHeader
+	if header == "" || strings.EqualFold(header, "Authorization") {
+		req.Header.Set("Authorization", "Bearer "+key)
+		return
+	}
+	req.Header.Set(header, key)
+}
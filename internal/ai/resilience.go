@@ -0,0 +1,238 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxBackoff caps the wait between retries however many there are.
+const maxBackoff = 5 * time.Second
+
+// ErrBreakerOpen is why a turn skipped the AI while the breaker is open.
+var ErrBreakerOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+
+// StatusError is an HTTP error status returned by a backend.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.Code)
+}
+
+// isTransient reports whether err is worth retrying straight away: a refused
+// or dropped connection, an overloaded server or a gateway error. Timeouts
+// are not, since the full timeout has already been spent waiting.
+func isTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code == http.StatusTooManyRequests || status.Code >= 500
+	}
+
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
+
+// FailureReason names why the AI could not answer, for counting fallbacks:
+// disabled, breaker_open, unavailable, timeout, network, http_<status>,
+// empty, guardrails or error.
+func FailureReason(err error) string {
+	var status *StatusError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrAIDisabled):
+		return "disabled"
+	case errors.Is(err, ErrBreakerOpen):
+		return "breaker_open"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrRejected):
+		return "guardrails"
+	case errors.Is(err, ErrEmptyReply):
+		return "empty"
+	case errors.As(err, &status):
+		return fmt.Sprintf("http_%d", status.Code)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	default:
+		return "error"
+	}
+}
+
+// isBackendFailure reports whether err means the backend itself is in
+// trouble, as opposed to a reply that was empty or rejected.
+func isBackendFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrEmptyReply) &&
+		!errors.Is(err, ErrRejected) &&
+		!errors.Is(err, ErrAIDisabled)
+}
+
+// backoff returns how long to wait before retry number attempt (from 0):
+// base doubled each time, give or take half, so that sessions sharing a
+// server do not retry in step.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	d := base << attempt
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// Breaker stops requests to a backend that keeps failing. After threshold
+// consecutive failures it opens and turns requests away without trying;
+// once cooldown has passed it lets a single trial request through, closing
+// again if it succeeds and reopening if it fails. A threshold of 0 never
+// opens.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool
+	lastErr  error
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a request may go ahead, moving an open breaker whose
+// cooldown has passed to half-open for a trial.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Enabled reports whether the breaker ever opens.
+func (b *Breaker) Enabled() bool {
+	return b.threshold > 0
+}
+
+// Ready reports whether Allow would let a request through, without taking
+// a half-open trial.
+func (b *Breaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return time.Since(b.openedAt) >= b.cooldown
+	case BreakerHalfOpen:
+		return !b.trial
+	default:
+		return true
+	}
+}
+
+// Record notes how a request that Allow let through went.
+func (b *Breaker) Record(err error) {
+	if isBackendFailure(err) {
+		b.failure(err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+	b.lastErr = nil
+}
+
+// Trip opens the breaker at once, as when a health check fails.
+func (b *Breaker) Trip(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+	b.open(err)
+}
+
+func (b *Breaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastErr = err
+	b.trial = false
+
+	if b.threshold <= 0 {
+		return
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.open(err)
+	}
+}
+
+func (b *Breaker) open(err error) {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.trial = false
+	b.lastErr = err
+}
+
+// State returns the breaker's state and the last failure, if any.
+func (b *Breaker) State() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state, b.lastErr
+}
@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	// Each step applies op and then checks the breaker's state. For "allow"
+	// steps allowed is what Allow must return.
+	type step struct {
+		op      string
+		want    string
+		allowed bool
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []step
+	}{
+		{
+			name:      "opens after threshold failures",
+			threshold: 2,
+			steps: []step{
+				{op: "fail", want: BreakerClosed},
+				{op: "allow", want: BreakerClosed, allowed: true},
+				{op: "fail", want: BreakerOpen},
+				{op: "allow", want: BreakerOpen, allowed: false},
+			},
+		},
+		{
+			name:      "success resets the failure count",
+			threshold: 2,
+			steps: []step{
+				{op: "fail", want: BreakerClosed},
+				{op: "ok", want: BreakerClosed},
+				{op: "fail", want: BreakerClosed},
+			},
+		},
+		{
+			name:      "rejected and empty replies are not failures",
+			threshold: 1,
+			steps: []step{
+				{op: "reject", want: BreakerClosed},
+				{op: "empty", want: BreakerClosed},
+			},
+		},
+		{
+			name:      "half-open allows one trial that closes it",
+			threshold: 1,
+			steps: []step{
+				{op: "fail", want: BreakerOpen},
+				{op: "cooldown", want: BreakerOpen},
+				{op: "allow", want: BreakerHalfOpen, allowed: true},
+				{op: "allow", want: BreakerHalfOpen, allowed: false},
+				{op: "ok", want: BreakerClosed},
+				{op: "allow", want: BreakerClosed, allowed: true},
+			},
+		},
+		{
+			name:      "failed trial opens it again",
+			threshold: 3,
+			steps: []step{
+				{op: "trip", want: BreakerOpen},
+				{op: "cooldown", want: BreakerOpen},
+				{op: "allow", want: BreakerHalfOpen, allowed: true},
+				{op: "fail", want: BreakerOpen},
+				{op: "allow", want: BreakerOpen, allowed: false},
+			},
+		},
+		{
+			name:      "disabled never opens",
+			threshold: 0,
+			steps: []step{
+				{op: "fail", want: BreakerClosed},
+				{op: "fail", want: BreakerClosed},
+				{op: "trip", want: BreakerClosed},
+				{op: "allow", want: BreakerClosed, allowed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		b := NewBreaker(tt.threshold, time.Hour)
+
+		for i, s := range tt.steps {
+			switch s.op {
+			case "allow":
+				if ready, allowed := b.Ready(), b.Allow(); allowed != s.allowed || ready != s.allowed {
+					t.Fatalf("%s: step %d: Ready, Allow = %v, %v, want %v", tt.name, i, ready, allowed, s.allowed)
+				}
+			case "fail":
+				b.Record(failure)
+			case "ok":
+				b.Record(nil)
+			case "reject":
+				b.Record(ErrRejected)
+			case "empty":
+				b.Record(ErrEmptyReply)
+			case "trip":
+				b.Trip(failure)
+			case "cooldown":
+				b.openedAt = time.Now().Add(-b.cooldown)
+			}
+
+			if state, _ := b.State(); state != s.want {
+				t.Fatalf("%s: step %d (%s): state = %v, want %v", tt.name, i, s.op, state, s.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package ai
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ScriptedAgent replays canned replies from a JSON script so sessions can be
+// demoed and regression-tested without a live model. In rule mode the first
+// rule whose pattern matches the input answers; otherwise replies are served
+// in order, one per turn.
+type ScriptedAgent struct {
+	mu           sync.Mutex
+	script       Script
+	rules        []*regexp.Regexp
+	next         int
+	offlineUntil time.Time
+	loadErr      error
+}
+
+type Script struct {
+	Available *bool        `json:"available,omitempty"`
+	Latency   Duration     `json:"latency,omitempty"`
+	Loop      bool         `json:"loop,omitempty"`
+	Default   string       `json:"default,omitempty"`
+	Rules     []ScriptStep `json:"rules,omitempty"`
+	Replies   []ScriptStep `json:"replies,omitempty"`
+}
+
+// ScriptStep is one scripted turn. Error makes the turn fail as a backend
+// would, Latency delays it, and Offline takes the agent down for that long
+// once the turn has been served.
+type ScriptStep struct {
+	Pattern  string   `json:"pattern,omitempty"`
+	Response string   `json:"response,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Latency  Duration `json:"latency,omitempty"`
+	Offline  Duration `json:"offline,omitempty"`
+}
+
+// Duration decodes JSON strings such as "1.5s" into a time.Duration.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"500ms\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+//go:embed scripts/luna-script.json
+var defaultScript []byte
+
+// LoadScriptedAgent replays the script at path, or the embedded example
+// script if path is empty.
+func LoadScriptedAgent(path string) (*ScriptedAgent, error) {
+	data := defaultScript
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read script: %w", err)
+		}
+	}
+
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to decode script %s: %w", path, err)
+	}
+
+	return NewScriptedAgent(script)
+}
+
+func NewScriptedAgent(script Script) (*ScriptedAgent, error) {
+	agent := &ScriptedAgent{script: script}
+
+	for i, rule := range script.Rules {
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, rule.Pattern, err)
+		}
+		agent.rules = append(agent.rules, pattern)
+	}
+
+	return agent, nil
+}
+
+func (sa *ScriptedAgent) IsAvailable() bool {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if sa.loadErr != nil {
+		return false
+	}
+	if sa.script.Available != nil && !*sa.script.Available {
+		return false
+	}
+
+	return time.Now().After(sa.offlineUntil)
+}
+
+func (sa *ScriptedAgent) ProcessCommand(input string, context GameContext) (string, error) {
+	if !sa.IsAvailable() {
+		return "", ErrUnavailable
+	}
+
+	sa.mu.Lock()
+	step, ok := sa.match(input)
+	latency := time.Duration(sa.script.Latency)
+	if ok && step.Offline > 0 {
+		sa.offlineUntil = time.Now().Add(time.Duration(step.Offline))
+	}
+	sa.mu.Unlock()
+
+	if ok && step.Latency > 0 {
+		latency = time.Duration(step.Latency)
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if !ok {
+		if sa.script.Default != "" {
+			return sa.script.Default, nil
+		}
+		return "", ErrEmptyReply
+	}
+
+	if step.Error != "" {
+		return "", errors.New(step.Error)
+	}
+
+	return step.Response, nil
+}
+
+// Err reports why the script could not be loaded, if it could not.
+func (sa *ScriptedAgent) Err() error {
+	return sa.loadErr
+}
+
+func newBrokenScriptedAgent(err error) *ScriptedAgent {
+	return &ScriptedAgent{loadErr: err}
+}
+
+func (sa *ScriptedAgent) match(input string) (ScriptStep, bool) {
+	if len(sa.rules) > 0 {
+		for i, pattern := range sa.rules {
+			if pattern.MatchString(input) {
+				return sa.script.Rules[i], true
+			}
+		}
+		return ScriptStep{}, false
+	}
+
+	step, ok := sa.peek()
+	if ok {
+		sa.next++
+	}
+	return step, ok
+}
+
+func (sa *ScriptedAgent) peek() (ScriptStep, bool) {
+	if len(sa.script.Replies) == 0 {
+		return ScriptStep{}, false
+	}
+
+	if sa.next >= len(sa.script.Replies) {
+		if !sa.script.Loop {
+			return ScriptStep{}, false
+		}
+		sa.next = 0
+	}
+
+	return sa.script.Replies[sa.next], true
+}
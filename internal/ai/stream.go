@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// leadLimit is how much text is buffered before the leading prefixes that
+// cleanAIResponse strips ("Pale Luna:", "Response:", "*") can be ruled out.
+const leadLimit = 24
+
+// streamCleaner applies cleanAIResponse's rules to text that arrives in
+// pieces. The start of the reply is held until its prefixes can be stripped,
+// and trailing whitespace and asterisks are held until more text proves they
+// are not the end. What is written out adds up to cleanAIResponse of the
+// whole reply.
+type streamCleaner struct {
+	started bool
+	lead    strings.Builder
+	tail    string
+}
+
+func (sc *streamCleaner) Write(chunk string) string {
+	if !sc.started {
+		sc.lead.WriteString(chunk)
+		if len(strings.TrimSpace(sc.lead.String())) < leadLimit {
+			return ""
+		}
+		sc.started = true
+		chunk = cleanLeading(sc.lead.String())
+	}
+
+	text := sc.tail + chunk
+	trimmed := strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '*'
+	})
+	sc.tail = text[len(trimmed):]
+
+	return trimmed
+}
+
+func (sc *streamCleaner) Flush() string {
+	if !sc.started {
+		sc.started = true
+		return cleanAIResponse(sc.lead.String())
+	}
+
+	tail := sc.tail
+	sc.tail = ""
+
+	// Everything written so far ends in text that cleanTrailing keeps, so
+	// the held tail cleans the same on its own as at the end of the reply.
+	return cleanTrailing(tail)
+}
+
+// cleanLeading strips leading whitespace and the "Pale Luna:", "Response:"
+// and "*" prefixes, in that order.
+func cleanLeading(response string) string {
+	for _, prefix := range []string{"Pale Luna:", "Response:", "*"} {
+		response = strings.TrimPrefix(strings.TrimLeftFunc(response, unicode.IsSpace), prefix)
+	}
+	return strings.TrimLeftFunc(response, unicode.IsSpace)
+}
+
+// cleanTrailing strips trailing whitespace and one closing "*".
+func cleanTrailing(response string) string {
+	response = strings.TrimSuffix(strings.TrimRightFunc(response, unicode.IsSpace), "*")
+	return strings.TrimRightFunc(response, unicode.IsSpace)
+}
+
+// relay carries chunks from the backend to whoever draws them without ever
+// making the backend wait, so a request slot is not held while a slow
+// client renders the reply.
+type relay struct {
+	mu     sync.Mutex
+	queued []string
+	done   bool
+	ready  chan struct{}
+}
+
+func newRelay() *relay {
+	return &relay{ready: make(chan struct{}, 1)}
+}
+
+func (r *relay) Write(chunk string) {
+	r.mu.Lock()
+	r.queued = append(r.queued, chunk)
+	r.mu.Unlock()
+	r.signal()
+}
+
+// Close marks the end of the reply; Drain returns once it has passed on
+// everything written before.
+func (r *relay) Close() {
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+	r.signal()
+}
+
+func (r *relay) signal() {
+	select {
+	case r.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Drain hands the chunks to onChunk in order, as they arrive, until Close.
+func (r *relay) Drain(onChunk func(string)) {
+	for range r.ready {
+		r.mu.Lock()
+		chunks, done := r.queued, r.done
+		r.queued = nil
+		r.mu.Unlock()
+
+		for _, chunk := range chunks {
+			onChunk(chunk)
+		}
+		if done {
+			return
+		}
+	}
+}
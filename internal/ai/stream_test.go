@@ -0,0 +1,41 @@
+package ai
+
+import "testing"
+
+func TestStreamCleanerMatchesFullText(t *testing.T) {
+	replies := []string{
+		"",
+		"   ",
+		"Dig.",
+		"*Dig.*",
+		"**",
+		"Pale Luna: The earth... remembers. Dig.",
+		"Pale Luna: The earth, it is old. Older than you.",
+		"  Response: *The gold waits beneath the old oak, where the soil is soft.*  \n",
+		"Pale Luna: Response: *The shovel remembers the rope, and the rope remembers you.",
+		"The hole is deep enough now, deeper than you think it is. **",
+		"The hole is deep enough now, deeper than you think it is. * *\n",
+		"The hole is deep enough now, deeper than you think it is.\u00a0*\u00a0",
+		"The hole is deep enough now. *Go* on... the earth is **listening**.",
+		"Pale Luna:\n\nThe moon is pale tonight, and so are you, my friend.\n\n",
+	}
+
+	for _, reply := range replies {
+		want := cleanAIResponse(reply)
+
+		// Backends stream whole characters, a few at a time.
+		runes := []rune(reply)
+		for size := 1; size <= 8; size++ {
+			cleaner := &streamCleaner{}
+			var got string
+			for i := 0; i < len(runes); i += size {
+				got += cleaner.Write(string(runes[i:min(i+size, len(runes))]))
+			}
+			got += cleaner.Flush()
+
+			if got != want {
+				t.Fatalf("streaming %q in %d-character chunks = %q, want %q", reply, size, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Trace is filled in by a client with the last request it made and what
+// came back, so a turn can be explained after the fact, along with the
+// tokens generated across every request and what the guardrails did. A nil
+// Trace records nothing.
+type Trace struct {
+	Prompt       string
+	Messages     []ChatMessage
+	Raw          string
+	Cleaned      string
+	Requests     int
+	Tokens       int
+	PromptTokens int
+	EvalDuration time.Duration
+	Guardrail    string
+	CacheErr     string
+}
+
+func (t *Trace) request(prompt string, messages []ChatMessage) {
+	if t == nil {
+		return
+	}
+	t.Requests++
+	t.Prompt = prompt
+	t.Messages = messages
+	t.Raw = ""
+	t.Cleaned = ""
+}
+
+func (t *Trace) reply(raw, cleaned string) {
+	if t == nil {
+		return
+	}
+	t.Raw = raw
+	t.Cleaned = cleaned
+}
+
+// usage adds what a request generated: tokens out, tokens in and how long
+// generation took.
+func (t *Trace) usage(tokens, promptTokens int, eval time.Duration) {
+	if t == nil {
+		return
+	}
+	t.Tokens += tokens
+	t.PromptTokens += promptTokens
+	t.EvalDuration += eval
+}
+
+func (t *Trace) intervened(action string) {
+	if t == nil {
+		return
+	}
+	t.Guardrail = action
+}
+
+func (t *Trace) cacheFailed(err error) {
+	if t == nil {
+		return
+	}
+	t.CacheErr = err.Error()
+}
+
+// TurnRecord is one line of the turn log: what the player typed, what was
+// sent and returned, what they were shown, where it came from and why.
+// Duration is the time to produce the reply and Render the time spent
+// drawing it afterwards.
+type TurnRecord struct {
+	Player   string
+	Input    string
+	Model    string
+	Trace    *Trace
+	Reply    string
+	Source   string
+	Err      error
+	Duration time.Duration
+	Render   time.Duration
+}
+
+// TurnLog writes a JSON line per turn for debugging what Pale Luna said.
+// The file is opened on the first turn and rotated when it grows too large.
+type TurnLog struct {
+	file   *rotatingFile
+	logger *slog.Logger
+}
+
+// NewTurnLog logs turns to path, keeping up to maxFiles rotated files of
+// about maxBytes each. maxBytes of 0 never rotates.
+func NewTurnLog(path string, maxBytes, maxFiles int) *TurnLog {
+	file := &rotatingFile{path: path, maxBytes: int64(maxBytes), maxFiles: maxFiles}
+	return &TurnLog{
+		file:   file,
+		logger: slog.New(slog.NewJSONHandler(file, nil)),
+	}
+}
+
+func (tl *TurnLog) Path() string {
+	return tl.file.path
+}
+
+// Err is the last error writing the log, if any.
+func (tl *TurnLog) Err() error {
+	tl.file.mu.Lock()
+	defer tl.file.mu.Unlock()
+	return tl.file.err
+}
+
+func (tl *TurnLog) Record(record TurnRecord) {
+	attrs := []slog.Attr{
+		slog.String("player", record.Player),
+		slog.String("input", record.Input),
+		slog.String("source", record.Source),
+		slog.String("reply", record.Reply),
+		slog.Int64("duration_ms", record.Duration.Milliseconds()),
+	}
+	if record.Render > 0 {
+		attrs = append(attrs, slog.Int64("render_ms", record.Render.Milliseconds()))
+	}
+	if record.Model != "" {
+		attrs = append(attrs, slog.String("model", record.Model))
+	}
+	if trace := record.Trace; trace != nil && trace.Requests > 0 {
+		if trace.Messages != nil {
+			attrs = append(attrs, slog.Any("prompt", trace.Messages))
+		} else {
+			attrs = append(attrs, slog.String("prompt", trace.Prompt))
+		}
+		attrs = append(attrs,
+			slog.String("raw", trace.Raw),
+			slog.String("cleaned", trace.Cleaned),
+			slog.Int("requests", trace.Requests),
+		)
+		if trace.Tokens > 0 {
+			attrs = append(attrs,
+				slog.Int("tokens", trace.Tokens),
+				slog.Int("prompt_tokens", trace.PromptTokens),
+				slog.Int64("eval_ms", trace.EvalDuration.Milliseconds()),
+			)
+		}
+		if trace.Guardrail != "" {
+			attrs = append(attrs, slog.String("guardrail", trace.Guardrail))
+		}
+	}
+	if record.Err != nil {
+		attrs = append(attrs, slog.String("error", record.Err.Error()))
+	}
+	if record.Trace != nil && record.Trace.CacheErr != "" {
+		attrs = append(attrs, slog.String("cache_error", record.Trace.CacheErr))
+	}
+
+	tl.logger.LogAttrs(context.Background(), slog.LevelInfo, "turn", attrs...)
+}
+
+// rotatingFile appends to path, moving it to path.1 (and path.1 to path.2,
+// and so on) once the next write would take it past maxBytes.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	err  error
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file != nil && rf.maxBytes > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		rf.file.Close()
+		rf.file = nil
+		rf.rotate()
+	}
+
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			rf.err = err
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	rf.err = err
+	return n, err
+}
+
+func (rf *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// rotate shifts the kept files along, dropping the oldest. With no files
+// to keep, the log simply starts over.
+func (rf *rotatingFile) rotate() {
+	if rf.maxFiles <= 0 {
+		os.Remove(rf.path)
+		return
+	}
+
+	for i := rf.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+	}
+	os.Rename(rf.path, rf.path+".1")
+}
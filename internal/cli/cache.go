@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+)
+
+// runCache reports on the reply cache, or clears it.
+func runCache(cfg *config.Config, opts *options, args []string) int {
+	cache := ai.NewResponseCache(ai.CachePath(cfg), cfg.AI.CacheMaxEntries, cfg.AI.CacheMaxBytes)
+
+	if len(args) == 0 {
+		entries, size := cache.Stats()
+		fmt.Printf("Cache: %s\n", cfg.AI.Cache)
+		fmt.Printf("File: %s\n", cache.Path())
+		fmt.Printf("Replies: %d (%d bytes)\n", entries, size)
+		return 0
+	}
+
+	if args[0] != "clear" {
+		fmt.Fprintf(os.Stderr, "Unknown cache command %q.\n", args[0])
+		return 2
+	}
+
+	entries, _ := cache.Stats()
+	if err := cache.Clear(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Cleared %d cached replies from %s\n", entries, cache.Path())
+	return 0
+}
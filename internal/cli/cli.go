@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/clock"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+type options struct {
+	configPath string
+	model      string
+	ollamaURL  string
+	noAI       bool
+	debug      bool
+	name       string
+	profile    string
+	seed       int64
+	clock      string
+	record     string
+	promptDir  string
+	locale     string
+	speed      float64
+	maxIdle    time.Duration
+	addr       string
+}
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(cfg *config.Config, opts *options, args []string) int
+}
+
+var commands = []command{
+	{name: "play", usage: "play", summary: "Start the game (default)", run: runPlay},
+	{name: "doctor", usage: "doctor", summary: "Check configuration, AI backend and data directory", run: runDoctor},
+	{name: "models", usage: "models [pull [name]]", summary: "List models installed on the Ollama server, or download one", run: runModels},
+	{name: "replay", usage: "replay <file>", summary: "Play back a recording, or feed a file of commands through a fresh game", run: runReplay},
+	{name: "cache", usage: "cache [clear]", summary: "Show the reply cache, or forget every cached reply", run: runCache},
+	{name: "prompts", usage: "prompts <export <dir>|show>", summary: "Write the built-in prompt templates to a directory, or show a rendered prompt", run: runPrompts},
+	{name: "serve", usage: "serve", summary: "Host Pale Luna for many players over TCP (telnet)", run: runServe},
+	{name: "web", usage: "web", summary: "Host Pale Luna in the browser over HTTP and WebSocket", run: runWeb},
+}
+
+// Run parses the command line, loads the configuration and dispatches to the
+// requested subcommand. It returns the process exit code.
+func Run(args []string) int {
+	opts := &options{}
+	flags := newFlagSet(opts)
+
+	rest, err := parseInterleaved(flags, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	name := "play"
+	if len(rest) > 0 {
+		name, rest = rest[0], rest[1:]
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", name)
+		usage(os.Stderr, flags)
+		return 2
+	}
+
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	if err := applyFlags(cfg, flags, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid setting: %v\n", err)
+		return 2
+	}
+
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "config: %s\n", warning)
+	}
+
+	return cmd.run(cfg, opts, rest)
+}
+
+func newFlagSet(opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("pale-luna", flag.ContinueOnError)
+
+	flags.StringVar(&opts.configPath, "config", "", "path to a config file (default: $XDG_CONFIG_HOME/pale-luna/config.toml)")
+	flags.StringVar(&opts.model, "model", "", "AI model to use")
+	flags.StringVar(&opts.ollamaURL, "ollama-url", "", "Ollama server URL")
+	flags.BoolVar(&opts.noAI, "no-ai", false, "disable AI and use the original responses")
+	flags.BoolVar(&opts.debug, "debug", false, "start in the debug realm")
+	flags.StringVar(&opts.name, "name", "", "play as this name without being asked")
+	flags.StringVar(&opts.profile, "profile", "", "continue an existing player profile")
+	flags.Int64Var(&opts.seed, "seed", 0, "seed for Pale Luna's random choices")
+	flags.StringVar(&opts.addr, "addr", "", "address for serve (default :4000) or web (default :4080) to listen on")
+	flags.StringVar(&opts.clock, "clock", "", "game clock: real, fixed:03:00, offset:-2h or accelerated:60@02:55")
+	flags.StringVar(&opts.locale, "locale", "", "language for game text and Pale Luna's replies (en, pt; default from LANG)")
+	flags.StringVar(&opts.promptDir, "prompt-dir", "", "load persona and prompt templates from this directory")
+	flags.StringVar(&opts.record, "record", "", "record each session as an asciicast v2 file in this directory")
+	flags.Float64Var(&opts.speed, "speed", 1, "playback speed for replaying a recording")
+	flags.DurationVar(&opts.maxIdle, "max-idle", 0, "cap pauses when replaying a recording (0 keeps them)")
+
+	flags.Usage = func() { usage(flags.Output(), flags) }
+	return flags
+}
+
+// parseInterleaved allows flags before and after the subcommand and its
+// arguments, returning the positional arguments in order.
+func parseInterleaved(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// applyFlags layers explicitly passed flags over file and environment values.
+func applyFlags(cfg *config.Config, flags *flag.FlagSet, opts *options) error {
+	var err error
+
+	flags.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch f.Name {
+		case "model":
+			err = cfg.Set("ai.model", opts.model, config.SourceFlag)
+		case "ollama-url":
+			err = cfg.Set("ai.ollama_url", opts.ollamaURL, config.SourceFlag)
+		case "no-ai":
+			err = cfg.Set("ai.enabled", strconv.FormatBool(!opts.noAI), config.SourceFlag)
+		case "debug":
+			err = cfg.Set("game.debug", strconv.FormatBool(opts.debug), config.SourceFlag)
+		case "seed":
+			err = cfg.Set("game.seed", strconv.FormatInt(opts.seed, 10), config.SourceFlag)
+		case "clock":
+			err = cfg.Set("game.clock", opts.clock, config.SourceFlag)
+		case "locale":
+			err = cfg.Set("game.locale", opts.locale, config.SourceFlag)
+		case "prompt-dir":
+			err = cfg.Set("ai.prompt_dir", opts.promptDir, config.SourceFlag)
+		case "record":
+			err = cfg.Set("game.record_dir", opts.record, config.SourceFlag)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := clock.Parse(cfg.Game.Clock); err != nil {
+		return err
+	}
+
+	if locale := cfg.Game.Locale; locale != "" && !strings.EqualFold(locale, "auto") && !i18n.IsSupported(locale) {
+		return fmt.Errorf("unsupported locale %q (available: %s)", locale, strings.Join(i18n.Supported(), ", "))
+	}
+
+	if _, err := ai.LoadPromptBuilder(cfg.AI.PromptDir); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: pale-luna [flags] [command] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.usage, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/profile"
+)
+
+func runDoctor(cfg *config.Config, opts *options, args []string) int {
+	failures := 0
+
+	ok := func(format string, a ...interface{}) { fmt.Printf("✅ "+format+"\n", a...) }
+	warn := func(format string, a ...interface{}) { fmt.Printf("⚠️  "+format+"\n", a...) }
+	fail := func(format string, a ...interface{}) {
+		failures++
+		fmt.Printf("❌ "+format+"\n", a...)
+	}
+
+	fmt.Println("🌙 Pale Luna - Doctor")
+	fmt.Println()
+
+	if cfg.File != "" {
+		ok("Config file: %s", cfg.File)
+	} else {
+		ok("Config file: none (defaults and environment)")
+	}
+	for _, warning := range cfg.Warnings {
+		warn("%s", warning)
+	}
+
+	manager := ai.NewAgentManager(cfg)
+	status := manager.GetStatus()
+
+	switch {
+	case !cfg.AI.Enabled:
+		warn("AI disabled; Pale Luna will use her original responses")
+	case manager.Health().Online:
+		ok("AI backend %v reachable at %v", status["backend"], status["endpoint"])
+	default:
+		fail("AI backend %v not reachable at %v", status["backend"], status["endpoint"])
+		if err, found := status["error"]; found {
+			fmt.Printf("   %v\n", err)
+		}
+	}
+
+	if cfg.AI.Enabled && status["backend"] == ai.BackendOllama {
+		models, err := ai.NewOllamaClient(&cfg.AI).ListModels()
+		switch {
+		case err != nil:
+			fail("Could not list models: %v", err)
+		case ai.HasModel(models, cfg.AI.Model):
+			ok("Model %s is installed", cfg.AI.Model)
+		default:
+			fail("Model %s is not installed (ollama pull %s)", cfg.AI.Model, cfg.AI.Model)
+		}
+	}
+
+	if cfg.AI.PromptDir != "" {
+		if _, err := ai.LoadPromptBuilder(cfg.AI.PromptDir); err != nil {
+			fail("%v", err)
+		} else {
+			ok("Prompt templates from %s", cfg.AI.PromptDir)
+		}
+	}
+
+	if cfg.AI.Log != "" {
+		if err := checkWritable(filepath.Dir(cfg.AI.Log)); err != nil {
+			fail("Turn log directory for %s is not writable: %v", cfg.AI.Log, err)
+		} else {
+			ok("Turns are logged to %s", cfg.AI.Log)
+		}
+	}
+
+	if cfg.Profile.Enabled {
+		dir := cfg.Profile.DataDir
+		if dir == "" {
+			dir = profile.DefaultDir()
+		}
+		if err := checkWritable(dir); err != nil {
+			fail("Data directory %s is not writable: %v", dir, err)
+		} else {
+			ok("Data directory %s is writable", dir)
+		}
+	} else {
+		warn("Profiles disabled; Pale Luna will forget you")
+	}
+
+	fmt.Println()
+	if failures > 0 {
+		fmt.Printf("%d problem(s) found.\n", failures)
+		return 1
+	}
+
+	fmt.Println("Everything is in place. She is waiting.")
+	return 0
+}
+
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+
+	return os.Remove(filepath.Clean(probe.Name()))
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+)
+
+// serveMetrics exposes the AI metrics in the Prometheus text format on
+// server.metrics_addr, if it is set. A port that cannot be opened is
+// reported but does not keep the game from starting.
+func serveMetrics(cfg *config.Config, metrics *ai.Metrics) {
+	addr := cfg.Server.MetricsAddr
+	if addr == "" {
+		return
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Metrics unavailable: %v\n", err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	go http.Serve(listener, mux)
+
+	fmt.Printf("📈 Metrics at http://%s/metrics\n", displayAddr(addr))
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/game"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+func runModels(cfg *config.Config, opts *options, args []string) int {
+	if len(args) > 0 {
+		if args[0] != "pull" || len(args) > 2 {
+			fmt.Fprintln(os.Stderr, "Usage: pale-luna models [pull [name]]")
+			return 2
+		}
+		name := cfg.AI.Model
+		if len(args) == 2 {
+			name = args[1]
+		}
+		return pullModel(cfg, name)
+	}
+
+	client := ai.NewOllamaClient(&cfg.AI)
+
+	models, err := client.ListModels()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not reach Ollama at %s: %v\n", cfg.AI.OllamaURL, err)
+		return 1
+	}
+
+	if len(models) == 0 {
+		fmt.Println("No models installed. Try: ollama pull " + cfg.AI.Model)
+		return 0
+	}
+
+	fmt.Printf("%-28s %10s  %-10s %s\n", "NAME", "SIZE", "FAMILY", "PARAMETERS")
+	for _, model := range models {
+		marker := " "
+		if model.Name == cfg.AI.Model {
+			marker = "*"
+		}
+		fmt.Printf("%-28s %10s  %-10s %s %s\n", model.Name, ai.FormatSize(model.Size), model.Details.Family, model.Details.ParameterSize, marker)
+	}
+
+	if !ai.HasModel(models, cfg.AI.Model) {
+		fmt.Printf("\n⚠️  Configured model %s is not installed.\n", cfg.AI.Model)
+	}
+
+	return 0
+}
+
+// pullModel downloads name onto the Ollama server with a progress bar.
+// Ctrl-C stops it; running it again picks up where it stopped.
+func pullModel(cfg *config.Config, name string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	msg := i18n.For(i18n.Resolve(cfg.Game.Locale))
+	if err := game.ShowPull(ctx, ai.NewAgentManager(cfg), name, os.Stdout, msg); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/game"
+)
+
+func runPlay(cfg *config.Config, opts *options, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "play takes no arguments, got %q\n", args)
+		return 2
+	}
+
+	if opts.profile != "" && !game.HasProfile(cfg, opts.profile) {
+		fmt.Fprintf(os.Stderr, "No profile named %q. Start one with --name %q.\n", opts.profile, opts.profile)
+		return 1
+	}
+
+	gameInstance := game.NewGame(cfg)
+
+	name := opts.profile
+	if name == "" {
+		name = opts.name
+	}
+
+	gameInstance.Play(name)
+
+	return 0
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+// runPrompts helps writers work on Pale Luna's prompts: export copies the
+// built-in templates somewhere editable, show renders the active ones for a
+// sample turn.
+func runPrompts(cfg *config.Config, opts *options, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: pale-luna prompts <export <dir>|show>")
+		return 2
+	}
+
+	switch args[0] {
+	case "export":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: pale-luna prompts export <dir>")
+			return 2
+		}
+		if err := ai.ExportDefaultPrompts(args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export prompts: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Prompt templates written to %s. Use them with --prompt-dir %s\n", args[1], args[1])
+		return 0
+
+	case "show":
+		prompts, err := ai.LoadPromptBuilder(cfg.AI.PromptDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		msg := i18n.For(i18n.Resolve(cfg.Game.Locale))
+		context := ai.GameContext{
+			PlayerName:   "Wanderer",
+			CurrentHour:  3,
+			SessionCount: 1,
+			Location:     "a dark room",
+			Locale:       msg.Locale,
+			Language:     msg.Language,
+		}
+		if cfg.AI.API == ai.APIGenerate {
+			fmt.Println(prompts.BuildPrompt("who are you", context))
+			return 0
+		}
+		for _, message := range prompts.BuildMessages("who are you", context) {
+			fmt.Printf("[%s]\n%s\n\n", message.Role, message.Content)
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown prompts command %q.\n", args[0])
+	return 2
+}
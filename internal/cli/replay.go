@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/game"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/record"
+)
+
+// runReplay plays back a session recording, or feeds each line of a command
+// file through a fresh game as if a player had typed it. Blank lines and #
+// comments are skipped. Profiles are not touched so replays are repeatable.
+func runReplay(cfg *config.Config, opts *options, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: pale-luna replay <file>")
+		return 2
+	}
+
+	if record.IsRecording(args[0]) {
+		return playRecording(cfg, args[0], opts)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open replay: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	cfg.Profile.Enabled = false
+	gameInstance := game.NewGame(cfg)
+	defer gameInstance.Close()
+
+	name := opts.name
+	if name == "" {
+		name = "Unknown"
+	}
+	gameInstance.StartAs(name)
+	out := gameInstance.Output()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() && gameInstance.GameRunning {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		gameInstance.Tick()
+		fmt.Fprintf(out, "> %s\n", line)
+		gameInstance.ProcessCommand(line)
+		fmt.Fprintln(out)
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read replay: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func playRecording(cfg *config.Config, path string, opts *options) int {
+	if opts.speed <= 0 {
+		fmt.Fprintln(os.Stderr, "--speed must be greater than zero")
+		return 2
+	}
+
+	err := record.Play(path, os.Stdout, record.PlayOptions{
+		Speed:   opts.speed,
+		MaxIdle: opts.maxIdle,
+		Markers: cfg.Game.Debug,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to play recording: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
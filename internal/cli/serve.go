@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/server"
+)
+
+func runServe(cfg *config.Config, opts *options, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "serve takes no arguments, got %q\n", args)
+		return 2
+	}
+
+	if opts.addr != "" {
+		if err := cfg.Set("server.addr", opts.addr, config.SourceFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid setting: %v\n", err)
+			return 2
+		}
+	}
+
+	srv := server.New(cfg)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		srv.Close()
+	}()
+
+	fmt.Printf("🌙 Pale Luna is listening on %s (telnet or netcat)\n", cfg.Server.Addr)
+	if srv.Agent().IsAIAvailable() {
+		fmt.Println("🤖 AI Integration: ACTIVE")
+	} else {
+		fmt.Println("⚠️  AI Integration: OFFLINE")
+	}
+	serveMetrics(cfg, srv.Agent().Metrics())
+
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "Server failed: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
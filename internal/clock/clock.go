@@ -0,0 +1,122 @@
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock is the game's source of the current time. Everything that decides
+// whether it is the witching hour asks a Clock rather than time.Now.
+type Clock interface {
+	Now() time.Time
+	String() string
+}
+
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
+
+func (Real) String() string { return "real" }
+
+// Fixed always reports the same instant.
+type Fixed struct {
+	At time.Time
+}
+
+func (f Fixed) Now() time.Time { return f.At }
+
+func (f Fixed) String() string { return "fixed:" + f.At.Format("15:04") }
+
+// Offset runs at normal speed but shifted by a fixed amount.
+type Offset struct {
+	By time.Duration
+}
+
+func (o Offset) Now() time.Time { return time.Now().Add(o.By) }
+
+func (o Offset) String() string { return "offset:" + o.By.String() }
+
+// Accelerated starts at From and runs Factor times faster than real time.
+type Accelerated struct {
+	From    time.Time
+	Factor  float64
+	started time.Time
+}
+
+func NewAccelerated(from time.Time, factor float64) *Accelerated {
+	return &Accelerated{From: from, Factor: factor, started: time.Now()}
+}
+
+func (a *Accelerated) Now() time.Time {
+	elapsed := time.Since(a.started)
+	return a.From.Add(time.Duration(float64(elapsed) * a.Factor))
+}
+
+func (a *Accelerated) String() string {
+	return fmt.Sprintf("accelerated:%gx@%s", a.Factor, a.From.Format("15:04"))
+}
+
+// Parse builds a clock from a spec:
+//
+//	real                   the system clock
+//	fixed:03:00            always 03:00 today
+//	offset:-2h30m          the system clock shifted by a duration
+//	accelerated:60         the system clock running 60 times faster
+//	accelerated:60@02:55   the same, starting from 02:55 today
+func Parse(spec string) (Clock, error) {
+	spec = strings.TrimSpace(spec)
+	kind, arg, _ := strings.Cut(spec, ":")
+
+	switch strings.ToLower(kind) {
+	case "", "real":
+		return Real{}, nil
+	case "fixed":
+		at, err := parseTimeOfDay(arg)
+		if err != nil {
+			return nil, err
+		}
+		return Fixed{At: at}, nil
+	case "offset":
+		by, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clock offset %q: %w", arg, err)
+		}
+		return Offset{By: by}, nil
+	case "accelerated":
+		factorSpec, fromSpec, hasFrom := strings.Cut(arg, "@")
+		factor, err := strconv.ParseFloat(strings.TrimSuffix(factorSpec, "x"), 64)
+		if err != nil || factor <= 0 {
+			return nil, fmt.Errorf("invalid clock factor %q", factorSpec)
+		}
+		from := time.Now()
+		if hasFrom {
+			if from, err = parseTimeOfDay(fromSpec); err != nil {
+				return nil, err
+			}
+		}
+		return NewAccelerated(from, factor), nil
+	default:
+		return nil, fmt.Errorf("unknown clock %q (want real, fixed, offset or accelerated)", kind)
+	}
+}
+
+func parseTimeOfDay(spec string) (time.Time, error) {
+	spec = strings.ToUpper(strings.TrimSpace(spec))
+	for _, layout := range []string{"15:04", "15:04:05", time.RFC3339} {
+		parsed, err := time.ParseInLocation(layout, spec, time.Local)
+		if err != nil {
+			continue
+		}
+		if layout == time.RFC3339 {
+			return parsed, nil
+		}
+
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(),
+			parsed.Hour(), parsed.Minute(), parsed.Second(), 0, time.Local), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (want HH:MM or RFC 3339)", spec)
+}
@@ -1,78 +1,420 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
 type Config struct {
-	AI AIConfig
+	AI      AIConfig
+	Game    GameConfig
+	Profile ProfileConfig
+	Server  ServerConfig
+
+	// File is the config file that was read, if any.
+	File     string
+	Warnings []string
+	sources  map[string]Source
 }
 
 type AIConfig struct {
 	Enabled         bool
+	Backend         string
 	OllamaURL       string
+	ScriptPath      string
 	Model           string
+	Pull            string
+	API             string
 	Timeout         time.Duration
 	MaxTokens       int
 	Temperature     float32
+	TopP            float32
 	FallbackEnabled bool
+	Stream          bool
+	MaxConcurrent   int
+	Retries         int
+	RetryBackoff    time.Duration
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	HealthInterval  time.Duration
+	Guardrails      string
+	MaxSentences    int
+	GuardRetries    int
+	PromptDir       string
+	Cache           string
+	CacheDir        string
+	CacheMaxEntries int
+	CacheMaxBytes   int
+	Log             string
+	LogMaxBytes     int
+	LogMaxFiles     int
+	OpenAI          OpenAIConfig
 }
 
-func Load() *Config {
+type OpenAIConfig struct {
+	BaseURL      string
+	APIKey       string
+	APIKeyHeader string
+}
+
+type GameConfig struct {
+	Debug           bool
+	Seed            int64
+	Clock           string
+	HistoryTurns    int
+	HistoryChars    int
+	TypewriterDelay time.Duration
+	RecordDir       string
+	Locale          string
+}
+
+type ServerConfig struct {
+	Addr        string
+	WebAddr     string
+	IdleTimeout time.Duration
+	Telnet      bool
+	MetricsAddr string
+	WebOrigins  string
+}
+
+type ProfileConfig struct {
+	Enabled bool
+	DataDir string
+}
+
+func Default() *Config {
 	return &Config{
 		AI: AIConfig{
-			Enabled:         getEnvBool("PALE_LUNA_AI_ENABLED", true),
-			OllamaURL:       getEnvString("PALE_LUNA_OLLAMA_URL", "http://localhost:11434"),
-			Model:           getEnvString("PALE_LUNA_AI_MODEL", "llama3.2:3b"),
-			Timeout:         getEnvDuration("PALE_LUNA_AI_TIMEOUT", 30*time.Second),
-			MaxTokens:       getEnvInt("PALE_LUNA_AI_MAX_TOKENS", 150),
-			Temperature:     getEnvFloat("PALE_LUNA_AI_TEMPERATURE", 0.8),
-			FallbackEnabled: getEnvBool("PALE_LUNA_AI_FALLBACK", true),
+			Enabled:         true,
+			Backend:         "ollama",
+			OllamaURL:       "http://localhost:11434",
+			Model:           "llama3.2:3b",
+			Pull:            "ask",
+			API:             "chat",
+			Timeout:         30 * time.Second,
+			MaxTokens:       150,
+			Temperature:     0.8,
+			TopP:            0,
+			FallbackEnabled: true,
+			Stream:          true,
+			MaxConcurrent:   2,
+			Retries:         2,
+			RetryBackoff:    500 * time.Millisecond,
+			BreakerFailures: 3,
+			BreakerCooldown: 30 * time.Second,
+			HealthInterval:  15 * time.Second,
+			Guardrails:      "regenerate",
+			MaxSentences:    3,
+			GuardRetries:    1,
+			Cache:           "off",
+			CacheMaxEntries: 1000,
+			CacheMaxBytes:   2 << 20,
+			LogMaxBytes:     10 << 20,
+			LogMaxFiles:     3,
+			OpenAI: OpenAIConfig{
+				BaseURL:      "http://localhost:8080/v1",
+				APIKeyHeader: "Authorization",
+			},
+		},
+		Game: GameConfig{
+			Clock:           "real",
+			HistoryTurns:    6,
+			HistoryChars:    1500,
+			TypewriterDelay: 30 * time.Millisecond,
+			Locale:          "auto",
 		},
+		Profile: ProfileConfig{
+			Enabled: true,
+		},
+		Server: ServerConfig{
+			Addr:        ":4000",
+			WebAddr:     ":4080",
+			IdleTimeout: 10 * time.Minute,
+			Telnet:      true,
+		},
+		sources: make(map[string]Source),
 	}
 }
 
-func getEnvString(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+type setting struct {
+	key    string
+	env    string
+	value  interface{}
+	secret bool
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "ai.enabled", env: "PALE_LUNA_AI_ENABLED", value: &c.AI.Enabled},
+		{key: "ai.backend", env: "PALE_LUNA_AI_BACKEND", value: &c.AI.Backend},
+		{key: "ai.ollama_url", env: "PALE_LUNA_OLLAMA_URL", value: &c.AI.OllamaURL},
+		{key: "ai.script", env: "PALE_LUNA_AI_SCRIPT", value: &c.AI.ScriptPath},
+		{key: "ai.model", env: "PALE_LUNA_AI_MODEL", value: &c.AI.Model},
+		{key: "ai.pull", env: "PALE_LUNA_AI_PULL", value: &c.AI.Pull},
+		{key: "ai.api", env: "PALE_LUNA_AI_API", value: &c.AI.API},
+		{key: "ai.timeout", env: "PALE_LUNA_AI_TIMEOUT", value: &c.AI.Timeout},
+		{key: "ai.max_tokens", env: "PALE_LUNA_AI_MAX_TOKENS", value: &c.AI.MaxTokens},
+		{key: "ai.temperature", env: "PALE_LUNA_AI_TEMPERATURE", value: &c.AI.Temperature},
+		{key: "ai.top_p", env: "PALE_LUNA_AI_TOP_P", value: &c.AI.TopP},
+		{key: "ai.fallback", env: "PALE_LUNA_AI_FALLBACK", value: &c.AI.FallbackEnabled},
+		{key: "ai.stream", env: "PALE_LUNA_AI_STREAM", value: &c.AI.Stream},
+		{key: "ai.max_concurrent", env: "PALE_LUNA_AI_MAX_CONCURRENT", value: &c.AI.MaxConcurrent},
+		{key: "ai.retries", env: "PALE_LUNA_AI_RETRIES", value: &c.AI.Retries},
+		{key: "ai.retry_backoff", env: "PALE_LUNA_AI_RETRY_BACKOFF", value: &c.AI.RetryBackoff},
+		{key: "ai.breaker_failures", env: "PALE_LUNA_AI_BREAKER_FAILURES", value: &c.AI.BreakerFailures},
+		{key: "ai.breaker_cooldown", env: "PALE_LUNA_AI_BREAKER_COOLDOWN", value: &c.AI.BreakerCooldown},
+		{key: "ai.health_interval", env: "PALE_LUNA_AI_HEALTH_INTERVAL", value: &c.AI.HealthInterval},
+		{key: "ai.guardrails", env: "PALE_LUNA_AI_GUARDRAILS", value: &c.AI.Guardrails},
+		{key: "ai.max_sentences", env: "PALE_LUNA_AI_MAX_SENTENCES", value: &c.AI.MaxSentences},
+		{key: "ai.guardrail_retries", env: "PALE_LUNA_AI_GUARDRAIL_RETRIES", value: &c.AI.GuardRetries},
+		{key: "ai.prompt_dir", env: "PALE_LUNA_PROMPT_DIR", value: &c.AI.PromptDir},
+		{key: "ai.cache", env: "PALE_LUNA_AI_CACHE", value: &c.AI.Cache},
+		{key: "ai.cache_dir", env: "PALE_LUNA_AI_CACHE_DIR", value: &c.AI.CacheDir},
+		{key: "ai.cache_max_entries", env: "PALE_LUNA_AI_CACHE_MAX_ENTRIES", value: &c.AI.CacheMaxEntries},
+		{key: "ai.cache_max_bytes", env: "PALE_LUNA_AI_CACHE_MAX_BYTES", value: &c.AI.CacheMaxBytes},
+		{key: "ai.log", env: "PALE_LUNA_AI_LOG", value: &c.AI.Log},
+		{key: "ai.log_max_bytes", env: "PALE_LUNA_AI_LOG_MAX_BYTES", value: &c.AI.LogMaxBytes},
+		{key: "ai.log_max_files", env: "PALE_LUNA_AI_LOG_MAX_FILES", value: &c.AI.LogMaxFiles},
+		{key: "ai.openai.url", env: "PALE_LUNA_OPENAI_URL", value: &c.AI.OpenAI.BaseURL},
+		{key: "ai.openai.api_key", env: "PALE_LUNA_OPENAI_API_KEY", value: &c.AI.OpenAI.APIKey, secret: true},
+		{key: "ai.openai.api_key_header", env: "PALE_LUNA_OPENAI_API_KEY_HEADER", value: &c.AI.OpenAI.APIKeyHeader},
+		{key: "game.debug", env: "PALE_LUNA_DEBUG", value: &c.Game.Debug},
+		{key: "game.seed", env: "PALE_LUNA_SEED", value: &c.Game.Seed},
+		{key: "game.clock", env: "PALE_LUNA_CLOCK", value: &c.Game.Clock},
+		{key: "game.history_turns", env: "PALE_LUNA_HISTORY_TURNS", value: &c.Game.HistoryTurns},
+		{key: "game.history_chars", env: "PALE_LUNA_HISTORY_CHARS", value: &c.Game.HistoryChars},
+		{key: "game.typewriter_delay", env: "PALE_LUNA_TYPEWRITER_DELAY", value: &c.Game.TypewriterDelay},
+		{key: "game.record_dir", env: "PALE_LUNA_RECORD_DIR", value: &c.Game.RecordDir},
+		{key: "game.locale", env: "PALE_LUNA_LOCALE", value: &c.Game.Locale},
+		{key: "profile.enabled", env: "PALE_LUNA_PROFILE_ENABLED", value: &c.Profile.Enabled},
+		{key: "profile.data_dir", env: "PALE_LUNA_DATA_DIR", value: &c.Profile.DataDir},
+		{key: "server.addr", env: "PALE_LUNA_SERVER_ADDR", value: &c.Server.Addr},
+		{key: "server.web_addr", env: "PALE_LUNA_WEB_ADDR", value: &c.Server.WebAddr},
+		{key: "server.idle_timeout", env: "PALE_LUNA_SERVER_IDLE_TIMEOUT", value: &c.Server.IdleTimeout},
+		{key: "server.telnet", env: "PALE_LUNA_SERVER_TELNET", value: &c.Server.Telnet},
+		{key: "server.metrics_addr", env: "PALE_LUNA_METRICS_ADDR", value: &c.Server.MetricsAddr},
+		{key: "server.web_origins", env: "PALE_LUNA_WEB_ORIGINS", value: &c.Server.WebOrigins},
 	}
-	return defaultValue
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.ParseBool(value); err == nil {
-			return parsed
+// Load builds the configuration from defaults, then the config file, then
+// PALE_LUNA_* environment variables. An empty path means the default file
+// location, which is allowed to be missing; an explicit path is not.
+// Command-line flags are layered on top by the caller through Set.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv("PALE_LUNA_CONFIG")
+		explicit = path != ""
+	}
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	if err := cfg.loadFile(path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
 		}
 	}
-	return defaultValue
+
+	cfg.loadEnv()
+
+	return cfg, nil
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.Atoi(value); err == nil {
-			return parsed
+func DefaultPath() string {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "pale-luna", "config.toml")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "pale-luna", "config.toml")
+}
+
+func (c *Config) loadFile(path string) error {
+	if path == "" {
+		return os.ErrNotExist
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	values, err := parseTOML(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	known := make(map[string]bool)
+	for _, s := range c.settings() {
+		known[s.key] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !known[key] {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("%s: unknown setting %q", path, key))
+			continue
+		}
+		if err := c.Set(key, values[key], SourceFile); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
 	}
-	return defaultValue
+
+	c.File = path
+	return nil
 }
 
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := time.ParseDuration(value); err == nil {
-			return parsed
+func (c *Config) loadEnv() {
+	for _, s := range c.settings() {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := c.Set(s.key, value, SourceEnv); err != nil {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("%s: %v (keeping %s)", s.env, err, c.display(s)))
 		}
 	}
-	return defaultValue
 }
 
-func getEnvFloat(key string, defaultValue float32) float32 {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.ParseFloat(value, 32); err == nil {
-			return float32(parsed)
+// Set parses value into the setting named key and records where it came from.
+func (c *Config) Set(key, value string, source Source) error {
+	for _, s := range c.settings() {
+		if s.key != key {
+			continue
+		}
+		if err := parseInto(s.value, value); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %w", value, key, err)
 		}
+		c.sources[key] = source
+		return nil
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+	Env    string
+}
+
+// Report lists every setting with its effective value and where it came from.
+// Secret values are masked.
+func (c *Config) Report() []Setting {
+	settings := c.settings()
+	report := make([]Setting, 0, len(settings))
+
+	for _, s := range settings {
+		source, ok := c.sources[s.key]
+		if !ok {
+			source = SourceDefault
+		}
+		report = append(report, Setting{
+			Key:    s.key,
+			Value:  c.display(s),
+			Source: source,
+			Env:    s.env,
+		})
+	}
+
+	return report
+}
+
+func (c *Config) display(s setting) string {
+	value := formatValue(s.value)
+	if s.secret && value != "" {
+		return "********"
+	}
+	return value
+}
+
+func parseInto(target interface{}, value string) error {
+	value = strings.TrimSpace(value)
+
+	switch ptr := target.(type) {
+	case *string:
+		*ptr = value
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("expected true or false")
+		}
+		*ptr = parsed
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		*ptr = parsed
+	case *int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		*ptr = parsed
+	case *float32:
+		parsed, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		*ptr = float32(parsed)
+	case *time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("expected a duration such as 30s")
+		}
+		*ptr = parsed
+	default:
+		return fmt.Errorf("unsupported setting type %T", target)
+	}
+
+	return nil
+}
+
+func formatValue(target interface{}) string {
+	switch ptr := target.(type) {
+	case *string:
+		return *ptr
+	case *bool:
+		return strconv.FormatBool(*ptr)
+	case *int:
+		return strconv.Itoa(*ptr)
+	case *int64:
+		return strconv.FormatInt(*ptr, 10)
+	case *float32:
+		return strconv.FormatFloat(float64(*ptr), 'g', -1, 32)
+	case *time.Duration:
+		return ptr.String()
+	default:
+		return fmt.Sprint(target)
 	}
-	return defaultValue
 }
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "top level keys",
+			input: "debug = true\nname = \"luna\"\n",
+			want:  map[string]string{"debug": "true", "name": "luna"},
+		},
+		{
+			name:  "tables qualify keys",
+			input: "[ai]\nmodel = \"llama3.2:3b\"\n[ai.openai]\nurl = 'http://localhost:8080/v1'\n",
+			want:  map[string]string{"ai.model": "llama3.2:3b", "ai.openai.url": "http://localhost:8080/v1"},
+		},
+		{
+			name:  "numbers drop underscores",
+			input: "[ai]\ncache_max_bytes = 2_097_152\ntemperature = 0.8\n",
+			want:  map[string]string{"ai.cache_max_bytes": "2097152", "ai.temperature": "0.8"},
+		},
+		{
+			name:  "comments outside strings",
+			input: "# settings\n[game]   # the game\ntitle = \"pale # luna\" # not part of it\nquote = \"say \\\"hi\\\" # here\"\n",
+			want:  map[string]string{"game.title": "pale # luna", "game.quote": "say \"hi\" # here"},
+		},
+		{
+			name:  "empty values are kept",
+			input: "[ai]\nlog = \"\"\n",
+			want:  map[string]string{"ai.log": ""},
+		},
+		{name: "unterminated table", input: "[ai\n", wantErr: true},
+		{name: "empty table", input: "[]\n", wantErr: true},
+		{name: "missing equals", input: "model\n", wantErr: true},
+		{name: "missing key", input: "= 1\n", wantErr: true},
+		{name: "missing value", input: "model =\n", wantErr: true},
+		{name: "bad string", input: "model = \"llama\n", wantErr: true},
+		{name: "bad literal string", input: "model = 'llama\n", wantErr: true},
+		{name: "duplicate key", input: "[ai]\nmodel = \"a\"\n[ai]\nmodel = \"b\"\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTOML(strings.NewReader(tt.input))
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: parseTOML = %v, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: parseTOML error = %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: parseTOML = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
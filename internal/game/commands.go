@@ -1,17 +1,24 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/clock"
 )
 
+// ProcessCommand runs one line of player input. Commands may be typed in
+// the player's language; anything that is not a command reaches Pale Luna
+// as it was written.
 func (g *State) ProcessCommand(input string) {
 	input = strings.TrimSpace(strings.ToLower(input))
+	command := g.msg.Canonical(input)
 
-	switch input {
+	switch command {
 	case "help":
 		g.showHelp()
 	case "time":
@@ -24,18 +31,121 @@ func (g *State) ProcessCommand(input string) {
 		g.handleDebugEncounter()
 	case "wake luna", "debug wake":
 		g.handleDebugWake()
+	case "clock":
+		g.showClock()
 	case "ai status":
 		g.showAIStatus()
+	case "ai stats":
+		g.showAIStats()
+	case "models":
+		g.showModels()
+	case "cache":
+		g.showCache()
+	case "cache clear":
+		g.handleCacheClear()
+	case "history":
+		g.showHistory()
+	case "config":
+		g.showConfig()
 	case "quit", "exit":
 		g.GameRunning = false
-		fmt.Println("Thank you for playing Pale Luna.")
+		g.say("quit")
 	case "":
 		return
 	default:
+		if spec, ok := strings.CutPrefix(input, "clock "); ok {
+			g.handleDebugClock(spec)
+			return
+		}
+		if command == "model" || strings.HasPrefix(command, "model ") {
+			g.handleModelCommand(strings.Fields(command), strings.Fields(input))
+			return
+		}
+		if cmd, ok := Parse(command); ok {
+			g.handleWorldCommand(cmd)
+			return
+		}
 		g.handleDynamicCommand(input)
 	}
 }
 
+// handleWorldCommand acts out a parsed command in the world.
+func (g *State) handleWorldCommand(cmd Command) {
+	wasSolved := g.world.Solved()
+
+	var response string
+	switch cmd.Verb {
+	case "look":
+		response = g.world.Look()
+	case "inventory":
+		response = g.world.ShowInventory()
+	case "take":
+		response = g.withObject(cmd, g.world.Take)
+	case "drop":
+		response = g.withObject(cmd, g.world.Drop)
+	case "use":
+		response = g.withObject(cmd, g.world.Use)
+	case "go":
+		switch {
+		case cmd.Object == "":
+			response = g.t("world.where")
+		case isDirection(cmd.Object):
+			response = g.world.Go(cmd.Object)
+		default:
+			response = g.t("world.no_way")
+		}
+	case "dig":
+		response = g.world.Dig()
+	case "cover":
+		response = g.world.Cover()
+	case "bury":
+		if cmd.Object == NounHole {
+			response = g.world.Cover()
+		} else {
+			response = g.withObject(cmd, g.world.Drop)
+		}
+	case "put":
+		switch cmd.Target {
+		case "", NounHole, NounGround:
+			response = g.withObject(cmd, g.world.Drop)
+		default:
+			response = g.t("world.nothing_happens")
+		}
+	}
+
+	fmt.Fprintln(g.out, response)
+
+	if !wasSolved && g.world.Solved() {
+		g.puzzleSolved()
+	}
+}
+
+// withObject applies action to the item cmd names, or asks which item. The
+// question repeats the verb as the player wrote it, unless the catalog names
+// it: Parse only sees the English command, so other languages do.
+func (g *State) withObject(cmd Command, action func(string) string) string {
+	switch {
+	case cmd.Object == "":
+		verb := cmd.Word
+		if key := "verb." + cmd.Verb; g.msg.Has(key) {
+			verb = g.t(key)
+		}
+		return g.t("world.what", verb)
+	case !isItem(cmd.Object):
+		return g.t("world.nothing_happens")
+	default:
+		return action(cmd.Object)
+	}
+}
+
+func isDirection(input string) bool {
+	switch input {
+	case "north", "south", "east", "west", "n", "s", "e", "w":
+		return true
+	}
+	return false
+}
+
 func (g *State) handleDynamicCommand(input string) {
 	context := ai.GameContext{
 		PlayerName:    g.PlayerName,
@@ -43,21 +153,71 @@ func (g *State) handleDynamicCommand(input string) {
 		SessionCount:  g.SessionCount,
 		DebugMode:     g.DebugMode,
 		PaleLunaAwake: g.PaleLunaAwake,
-		RecentHistory: []string{}, // TODO: Implement history tracking
+		Location:      g.world.Location(),
+		Inventory:     g.world.Inventory(),
+		PuzzleStep:    g.world.Step().String(),
+		RecentHistory: g.history.Turns(),
 		LastCommand:   input,
+		Locale:        g.msg.Locale,
+		Language:      g.msg.Language,
 	}
 
+	start := time.Now()
 	if g.IsAIEnabled() {
-		response := g.aiAgent.ProcessInput(input, context)
-		fmt.Println(response)
+		var firstChunk time.Duration
+		reply := g.aiAgent.Reply(input, context, func(chunk string) {
+			if firstChunk == 0 {
+				firstChunk = time.Since(start)
+			}
+			g.typewrite(chunk)
+		})
+		fmt.Fprintln(g.out)
+		if g.recorder != nil {
+			g.recorder.Marker(fmt.Sprintf("ai latency %s, total %s",
+				firstChunk.Round(time.Millisecond), time.Since(start).Round(time.Millisecond)))
+		}
+		g.metrics.Observe(reply)
+		g.history.Add(input, reply.Text)
+		return
+	}
+
+	// The AI is off or unreachable, so the game answers by itself.
+	reason := ai.ErrUnavailable
+	if !g.config.AI.Enabled {
+		reason = ai.ErrAIDisabled
+	}
+
+	if response, ok := g.aiAgent.Cached(input, context); ok {
+		found := time.Since(start)
+		g.typewrite(response)
+		fmt.Fprintln(g.out)
+		g.history.Add(input, response)
+		g.record(input, ai.Reply{Text: response, Source: ai.SourceCache, Err: reason, Duration: found, Render: time.Since(start) - found})
 		return
 	}
 
+	// Keep what the legacy handlers print, so the history and the turn log
+	// see the same reply the player did.
+	var shown strings.Builder
+	out := g.out
+	g.out = io.MultiWriter(out, &shown)
 	g.handleLegacyCommands(input)
+	g.out = out
+
+	response := strings.TrimSpace(shown.String())
+	g.history.Add(input, response)
+	g.record(input, ai.Reply{Text: response, Source: ai.SourceLegacy, Err: reason, Duration: time.Since(start)})
+}
+
+// record counts a turn the game answered without the AI, in this session's
+// metrics and the AgentManager's.
+func (g *State) record(input string, reply ai.Reply) {
+	g.metrics.Observe(reply)
+	g.aiAgent.Record(g.PlayerName, input, reply)
 }
 
 func (g *State) handleLegacyCommands(input string) {
-	switch input {
+	switch g.msg.Canonical(input) {
 	case "pale luna", "paleluna":
 		g.handlePaleLunaCommand()
 	case "sleep":
@@ -74,114 +234,341 @@ func (g *State) handleLegacyCommands(input string) {
 }
 
 func (g *State) showHelp() {
-	fmt.Println("Available commands:")
-	fmt.Println("  help        - Show this help message")
-	fmt.Println("  time        - Show current time")
-	fmt.Println("  status      - Show game status")
-	fmt.Println("  pale luna   - The primary invocation")
-	fmt.Println("  debug       - Toggle debug mode")
+	g.say("help")
 
 	if g.IsAIEnabled() {
-		fmt.Println("  ai status   - Show AI system status")
-		fmt.Println()
-		fmt.Println("💡 AI Enhanced: You can speak naturally to Pale Luna!")
-		fmt.Println("   Try: 'hello', 'who are you?', 'what do you want?'")
+		g.say("help.ai")
 	}
 
-	fmt.Println("  quit        - Exit the game")
+	g.say("help.quit")
 
 	if g.DebugMode {
-		fmt.Println()
-		fmt.Println("Debug commands:")
-		fmt.Println("  force encounter - Force a Pale Luna encounter")
-		fmt.Println("  wake luna       - Temporarily wake Pale Luna")
+		fmt.Fprintln(g.out)
+		g.say("help.debug")
 	}
 
-	fmt.Println()
-	fmt.Println("Try typing anything... Pale Luna is listening.")
+	fmt.Fprintln(g.out)
+	g.say("help.listening")
 }
 
 func (g *State) showTime() {
-	now := time.Now()
-	fmt.Printf("Current time: %s\n", now.Format("15:04:05 MST"))
+	now := g.Now()
+	g.say("time.now", now.Format("15:04:05 MST"))
 
 	if g.CurrentHour == 3 {
-		fmt.Println("...the witching hour approaches...")
+		g.say("time.witching")
 	} else if g.CurrentHour >= 0 && g.CurrentHour <= 5 {
-		fmt.Println("The night is deep and dark.")
+		g.say("time.night")
 	}
 }
 
 func (g *State) showStatus() {
-	fmt.Printf("Player: %s\n", g.PlayerName)
-	fmt.Printf("Session: #%d\n", g.SessionCount)
-	fmt.Printf("Current time: %s\n", time.Now().Format("15:04:05"))
+	g.say("status.player", g.PlayerName)
+	g.say("status.session", g.SessionCount)
+	if count := g.encounterCount(); count > 0 {
+		g.say("status.encounters", count)
+	}
+	g.say("status.time", g.Now().Format("15:04:05"))
 
-	if g.IsAIEnabled() {
-		fmt.Println("AI Status: ACTIVE")
+	if g.IsAIOnline() {
+		g.say("status.ai_active")
 	} else {
-		fmt.Println("AI Status: OFFLINE (using fallback responses)")
+		g.say("status.ai_offline")
 	}
 
 	if g.DebugMode {
-		fmt.Println("Debug mode: ENABLED")
+		g.say("status.debug")
 	}
 
 	if g.PaleLunaAwake {
-		fmt.Println("Entity Status: Pale Luna is awake")
+		g.say("status.awake")
 	} else {
-		fmt.Println("Entity Status: All is quiet")
+		g.say("status.quiet")
 	}
 }
 
 func (g *State) showAIStatus() {
-	if !g.IsAIEnabled() {
-		fmt.Println("AI System: OFFLINE")
-		fmt.Println("Pale Luna speaks through ancient, predefined whispers...")
+	status := g.GetAIStatus()
+	if online, _ := status["ai_available"].(bool); !online {
+		g.say("ai.offline")
+		if err, ok := status["error"]; ok {
+			g.say("ai.error", err)
+		}
+		if checked, ok := status["checked"].(time.Time); ok && !checked.IsZero() {
+			g.say("ai.checked", checked.Format("15:04:05"))
+		}
+		g.say("ai.offline_whispers")
 		return
 	}
 
-	status := g.GetAIStatus()
-	fmt.Println("AI System Status:")
-	fmt.Printf("  Model: %v\n", status["model"])
-	fmt.Printf("  Endpoint: %v\n", status["ollama_url"])
-	fmt.Printf("  Available: %v\n", status["ai_available"])
-	fmt.Println()
-	fmt.Println("The digital consciousness stirs within the machine...")
+	g.say("ai.status")
+	g.say("ai.backend", status["backend"])
+	g.say("ai.model", status["model"])
+	g.say("ai.endpoint", status["endpoint"])
+	if status["backend"] == ai.BackendOllama {
+		g.say("ai.api", status["api"])
+	}
+	g.say("ai.available", status["ai_available"],
+		status["since"].(time.Time).Format("15:04:05"), status["checked"].(time.Time).Format("15:04:05"))
+	g.say("ai.breaker", status["breaker"])
+
+	recent, total := g.aiAgent.Guardrails().Interventions()
+	g.say("ai.guardrails", status["guardrails"], total)
+	if g.DebugMode {
+		for _, intervention := range lastInterventions(recent, 5) {
+			fmt.Fprintf(g.out, "    %s %q: %v -> %s\n",
+				intervention.Time.Format("15:04:05"), intervention.Input, intervention.Violations, intervention.Action)
+		}
+	}
+	entries, size := g.aiAgent.Cache().Stats()
+	g.say("ai.cache", status["cache"], entries, size)
+	if err, ok := status["cache_error"]; ok {
+		g.say("ai.cache_error", err)
+	}
+	if path, ok := status["log"]; ok {
+		g.say("ai.log", path)
+	}
+
+	fmt.Fprintln(g.out)
+	g.say("ai.stirs")
+}
+
+func (g *State) showAIStats() {
+	stats := g.metrics.Summary()
+
+	g.say("stats.title", time.Since(stats.Since).Round(time.Second))
+	if stats.Replies == 0 {
+		g.say("stats.none")
+		return
+	}
+
+	g.say("stats.replies", stats.Replies, ai.FormatCounts(stats.Sources))
+	if len(stats.Fallbacks) > 0 {
+		g.say("stats.fallbacks", stats.FallbackRate()*100, ai.FormatCounts(stats.Fallbacks))
+	} else {
+		g.say("stats.no_fallbacks")
+	}
+
+	if latency := stats.Latency; latency.Count > 0 {
+		g.say("stats.latency", seconds(latency.Mean), seconds(latency.P50), seconds(latency.P95), seconds(latency.Max))
+	}
+	if tokens := stats.Tokens; tokens.Count > 0 {
+		g.say("stats.tokens", int(tokens.Sum), tokens.Mean, tokens.Max)
+	}
+	if speed := stats.TokensPerSecond; speed.Count > 0 {
+		g.say("stats.speed", speed.Mean, speed.P50, speed.P95)
+	}
+	if len(stats.Interventions) > 0 {
+		g.say("stats.guardrails_by", ai.Total(stats.Interventions), ai.FormatCounts(stats.Interventions))
+	} else {
+		g.say("stats.guardrails", 0)
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
+
+func lastInterventions(interventions []ai.Intervention, n int) []ai.Intervention {
+	if len(interventions) > n {
+		return interventions[len(interventions)-n:]
+	}
+	return interventions
+}
+
+func (g *State) showConfig() {
+	if g.config.File != "" {
+		g.say("config.file", g.config.File)
+	} else {
+		g.say("config.none")
+	}
+
+	for _, setting := range g.config.Report() {
+		fmt.Fprintf(g.out, "  %-24s %-32s (%s)\n", setting.Key, setting.Value, setting.Source)
+	}
+}
+
+func (g *State) showHistory() {
+	turns := g.history.Turns()
+	if len(turns) == 0 {
+		g.say("history.empty")
+		return
+	}
+
+	g.say("history.title")
+	for _, turn := range turns {
+		fmt.Fprintf(g.out, "  > %s\n", turn.Input)
+		fmt.Fprintf(g.out, "    %s\n", turn.Response)
+	}
 }
 
 func (g *State) toggleDebugMode() {
 	g.DebugMode = !g.DebugMode
 	if g.DebugMode {
-		fmt.Println("Debug mode ENABLED")
-		fmt.Println("You have entered the debug realm where time holds no power.")
-		fmt.Println("Use 'force encounter' to trigger an encounter.")
-		fmt.Println("Use 'wake luna' to temporarily wake Pale Luna.")
+		g.say("debug.enabled")
 	} else {
-		fmt.Println("Debug mode DISABLED")
-		fmt.Println("Reality reasserts itself. Normal time-based behavior restored.")
+		g.say("debug.disabled")
 		g.checkPaleLunaConditions()
 	}
 }
 
 func (g *State) handleDebugEncounter() {
 	if !g.DebugMode {
-		fmt.Println("Unknown command. The shadows do not recognize your words.")
+		g.say("debug.encounter_denied")
 		return
 	}
 
-	fmt.Println("[DEBUG] Forcing Pale Luna encounter...")
-	fmt.Println()
+	g.say("debug.encounter")
+	fmt.Fprintln(g.out)
 	g.paleLunaEncounter()
 }
 
 func (g *State) handleDebugWake() {
 	if !g.DebugMode {
-		fmt.Println("Unknown command. The darkness remains silent.")
+		g.say("debug.wake_denied")
 		return
 	}
 
 	g.PaleLunaAwake = true
-	fmt.Println("[DEBUG] Pale Luna has been awakened in the debug realm.")
-	fmt.Println("She will remain conscious until you exit this realm or restart the game.")
+	g.say("debug.wake")
+}
+
+func (g *State) showModels() {
+	models, err := g.aiAgent.ListModels()
+	if err != nil {
+		g.say("models.error", err)
+		return
+	}
+	if len(models) == 0 {
+		g.say("models.none", g.aiAgent.Model())
+		return
+	}
+
+	current := g.aiAgent.Model()
+	g.say("models.title")
+	for _, model := range models {
+		marker := " "
+		if ai.HasModel([]ai.ModelInfo{model}, current) {
+			marker = "*"
+		}
+		fmt.Fprintf(g.out, "  %s %-28s %10s  %-10s %s\n", marker, model.Name,
+			ai.FormatSize(model.Size), model.Details.Family, model.Details.ParameterSize)
+	}
+
+	if !ai.HasModel(models, current) {
+		g.say("model.missing", current, current)
+	}
+}
+
+// handleModelCommand runs "model use <name>" and "model info [name]". words
+// is the command in English; typed keeps the player's spelling of the name.
+func (g *State) handleModelCommand(words, typed []string) {
+	name := ""
+	if len(typed) > 2 {
+		name = strings.Join(typed[2:], " ")
+	}
+
+	switch {
+	case len(words) >= 2 && words[1] == "info":
+		g.showModelInfo(name)
+	case len(words) >= 3 && words[1] == "use":
+		g.useModel(name)
+	case len(words) >= 2 && words[1] == "pull":
+		if name == "" {
+			name = g.aiAgent.Model()
+		}
+		if g.pullModel(name) && name != g.aiAgent.Model() {
+			g.say("model.use_hint", name)
+		}
+	default:
+		g.say("model.usage")
+	}
+}
+
+func (g *State) useModel(name string) {
+	if !g.AllowModelSwitch {
+		g.say("model.switch_denied")
+		return
+	}
+
+	if err := g.aiAgent.UseModel(name); err != nil {
+		g.say("model.error", err)
+		var missing *ai.ModelNotInstalledError
+		if errors.As(err, &missing) && g.AllowModelPull {
+			g.say("model.pull_hint", name)
+		}
+		return
+	}
+	g.say("model.switched", name)
+}
+
+func (g *State) showModelInfo(name string) {
+	if name == "" {
+		name = g.aiAgent.Model()
+	}
+
+	show, err := g.aiAgent.ShowModel(name)
+	if err != nil {
+		g.say("model.error", err)
+		return
+	}
+
+	g.say("model.info", name)
+	g.say("model.family", show.Details.Family, show.Details.ParameterSize, show.Details.QuantizationLevel)
+	if length := show.ContextLength(); length > 0 {
+		g.say("model.context", length)
+	}
+	if show.Parameters != "" {
+		g.say("model.parameters")
+		for _, line := range strings.Split(strings.TrimSpace(show.Parameters), "\n") {
+			fmt.Fprintf(g.out, "    %s\n", strings.Join(strings.Fields(line), " "))
+		}
+	}
+	if show.Template != "" {
+		g.say("model.template")
+		for _, line := range strings.Split(strings.TrimSpace(show.Template), "\n") {
+			fmt.Fprintf(g.out, "    %s\n", line)
+		}
+	}
+}
+
+func (g *State) showCache() {
+	cache := g.aiAgent.Cache()
+	entries, size := cache.Stats()
+	g.say("cache.stats", g.GetAIStatus()["cache"], entries, size, cache.Path())
+}
+
+func (g *State) handleCacheClear() {
+	if !g.AllowModelSwitch {
+		g.say("cache.clear_denied")
+		return
+	}
+
+	cache := g.aiAgent.Cache()
+	entries, _ := cache.Stats()
+	if err := cache.Clear(); err != nil {
+		g.say("cache.error", err)
+		return
+	}
+	g.say("cache.cleared", entries)
+}
+
+func (g *State) showClock() {
+	g.say("clock.show", g.clock, g.Now().Format("15:04:05"))
+}
+
+func (g *State) handleDebugClock(spec string) {
+	if !g.DebugMode {
+		g.say("debug.clock_denied")
+		return
+	}
+
+	c, err := clock.Parse(spec)
+	if err != nil {
+		g.say("debug.clock_error", err)
+		return
+	}
+
+	g.SetClock(c)
+	g.say("debug.clock_set", c, g.Now().Format("15:04:05"))
 }
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Terminal controls the screen beyond plain text output.
+type Terminal interface {
+	Clear()
+}
+
+// Console is where a game reads the player's input and writes its output.
+type Console struct {
+	In       io.Reader
+	Out      io.Writer
+	Terminal Terminal
+}
+
+// StdConsole is the local terminal the game was started from.
+func StdConsole() Console {
+	return Console{
+		In:       os.Stdin,
+		Out:      os.Stdout,
+		Terminal: SystemTerminal{Out: os.Stdout},
+	}
+}
+
+// ANSITerminal clears the screen with escape sequences only, which suits
+// remote clients and terminal emulators.
+type ANSITerminal struct {
+	Out io.Writer
+}
+
+func (t ANSITerminal) Clear() {
+	fmt.Fprint(t.Out, "\033[2J\033[H")
+}
+
+// SystemTerminal also runs the platform's clear command, for local consoles
+// that ignore escape sequences.
+type SystemTerminal struct {
+	Out *os.File
+}
+
+func (t SystemTerminal) Clear() {
+	ANSITerminal{Out: t.Out}.Clear()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "cls")
+	} else {
+		cmd = exec.Command("clear")
+	}
+	cmd.Stdout = t.Out
+	cmd.Run()
+}
+
+// NopTerminal ignores screen control, for tests and recordings.
+type NopTerminal struct{}
+
+func (NopTerminal) Clear() {}
+
+func newConsoleReader(in io.Reader) *bufio.Reader {
+	if reader, ok := in.(*bufio.Reader); ok {
+		return reader
+	}
+	return bufio.NewReader(in)
+}
@@ -1,60 +1,92 @@
 package game
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
+	"strings"
+	"time"
 )
 
-func ClearScreen() {
-	fmt.Print("\033[2J\033[H")
+func (g *State) ClearScreen() {
+	g.term.Clear()
+}
+
+func (g *State) ShowTitle() {
+	fmt.Fprintln(g.out, "═══════════════════════════════════════")
+	fmt.Fprintln(g.out, "              PALE LUNA")
+	fmt.Fprintln(g.out, strings.TrimRight(center(g.t("title.subtitle"), 39), " "))
+	fmt.Fprintln(g.out, "═══════════════════════════════════════")
+	fmt.Fprintln(g.out)
+}
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/c", "cls")
+func (g *State) ShowAIBanner() {
+	if g.IsAIEnabled() {
+		g.say("banner.ai_active")
+		if missing := g.aiAgent.MissingModel(); missing != "" {
+			g.say("model.missing", missing, missing)
+			g.offerPull(missing)
+		}
 	} else {
-		cmd = exec.Command("clear")
+		g.say("banner.ai_offline")
+	}
+	fmt.Fprintln(g.out)
+}
+
+func (g *State) ShowIntroduction() {
+	g.say("intro")
+	fmt.Fprintln(g.out)
+	g.pressEnter()
+}
+
+// t looks a message up in the player's language.
+func (g *State) t(key string, args ...interface{}) string {
+	return g.msg.T(key, args...)
+}
+
+// say prints a message in the player's language on its own line.
+func (g *State) say(key string, args ...interface{}) {
+	fmt.Fprintln(g.out, g.t(key, args...))
+}
+
+// banner frames text in the block border used for Pale Luna's moments.
+func (g *State) banner(text string) {
+	fmt.Fprintln(g.out, "▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
+	fmt.Fprintln(g.out, "▓                                      ▓")
+	fmt.Fprintln(g.out, "▓"+center(text, 38)+"▓")
+	fmt.Fprintln(g.out, "▓                                      ▓")
+	fmt.Fprintln(g.out, "▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
+}
+
+// center pads text to width, cutting it short if it does not fit.
+func center(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) >= width {
+		return string(runes[:width])
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Run()
-}
-
-func ShowTitle() {
-	fmt.Println("═══════════════════════════════════════")
-	fmt.Println("              PALE LUNA")
-	fmt.Println("        Digital Consciousness")
-	fmt.Println("═══════════════════════════════════════")
-	fmt.Println()
-}
-
-func ShowIntroduction() {
-	fmt.Println("Welcome to Pale Luna.")
-	fmt.Println()
-	fmt.Println("Legend speaks of this programme discovered on an abandoned computer,")
-	fmt.Println("with no documentation or creator information. Players reported strange")
-	fmt.Println("occurrences when interacting at specific times...")
-	fmt.Println()
-	fmt.Println("The original consisted of simple text commands and responses.")
-	fmt.Println("Some say it's just clever programming. Others believe something more")
-	fmt.Println("sinister lurks within the code.")
-	fmt.Println()
-	fmt.Println("This version has been... enhanced. The entity within has grown")
-	fmt.Println("more sophisticated, more aware. It can now understand and respond")
-	fmt.Println("to natural language through advanced AI integration.")
-	fmt.Println()
-	fmt.Println("You have been warned.")
-	fmt.Println()
-	pressEnter()
-}
-
-func pressEnter() {
-	fmt.Print("Press Enter to continue...")
-	reader := bufio.NewReader(os.Stdin)
-	_, err := reader.ReadString('\n')
+
+	left := (width - len(runes)) / 2
+	return strings.Repeat(" ", left) + text + strings.Repeat(" ", width-len(runes)-left)
+}
+
+// typewrite prints Luna's words one rune at a time at the configured pace.
+func (g *State) typewrite(text string) {
+	delay := g.config.Game.TypewriterDelay
+	if delay <= 0 {
+		fmt.Fprint(g.out, text)
+		return
+	}
+
+	for _, r := range text {
+		fmt.Fprint(g.out, string(r))
+		time.Sleep(delay)
+	}
+}
+
+func (g *State) pressEnter() {
+	fmt.Fprint(g.out, g.t("press_enter"))
+	_, err := g.in.ReadString('\n')
 	if err != nil {
-		fmt.Printf("\nError reading input: %v\n", err)
+		fmt.Fprintln(g.out)
+		g.say("error.input", err)
 	}
-	ClearScreen()
+	g.ClearScreen()
 }
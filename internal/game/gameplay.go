@@ -1,59 +1,208 @@
 package game
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
 
+// Play runs a whole session: title, AI banner, player setup (with the
+// introduction for a new player), the game loop and the farewell. An empty
+// name means the player is asked who they are.
+func (g *State) Play(name string) {
+	g.ClearScreen()
+	g.ShowTitle()
+	g.ShowAIBanner()
+
+	if name != "" {
+		g.StartAs(name)
+	} else {
+		g.SetupPlayer()
+	}
+
+	g.MainGameLoop()
+
+	fmt.Fprintln(g.out)
+	g.say("farewell")
+
+	if err := g.Close(); err != nil {
+		g.say("error.recording_save", err)
+	}
+}
+
 func (g *State) SetupPlayer() {
-	reader := bufio.NewReader(os.Stdin)
+	if last := g.lastProfileName(); last != "" {
+		g.say("setup.familiar", last)
+		fmt.Fprint(g.out, g.t("setup.continue", last))
+		answer, _ := g.in.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+
+		if answer == "" || g.isYes(answer) {
+			g.loadProfile(last)
+			g.greetPlayer()
+			return
+		}
+		fmt.Fprintln(g.out)
+	}
 
-	fmt.Print("Enter your name: ")
-	name, _ := reader.ReadString('\n')
-	g.PlayerName = strings.TrimSpace(name)
+	fmt.Fprint(g.out, g.t("setup.name"))
+	name, _ := g.in.ReadString('\n')
+	name = strings.TrimSpace(name)
 
-	if g.PlayerName == "" {
-		g.PlayerName = "Unknown"
+	if name == "" {
+		name = g.t("setup.unknown")
 	}
 
-	fmt.Printf("\nHello, %s. Welcome to Pale Luna.\n", g.PlayerName)
+	g.loadProfile(name)
+	g.greetPlayer()
+}
+
+func (g *State) isYes(answer string) bool {
+	for _, yes := range g.msg.List("setup.yes") {
+		if answer == yes {
+			return true
+		}
+	}
+	return false
+}
+
+// StartAs skips the interactive prompt and plays as the named player,
+// continuing their profile if one exists.
+func (g *State) StartAs(name string) {
+	g.loadProfile(name)
+	g.greetPlayer()
+}
+
+// greetPlayer welcomes the player back, or shows a new player the
+// introduction first.
+func (g *State) greetPlayer() {
+	if g.profile == nil || g.profile.FirstTime {
+		g.ShowIntroduction()
+	}
+
+	if g.profile != nil && !g.profile.FirstTime {
+		fmt.Fprintln(g.out)
+		g.say("greet.returning", g.PlayerName)
+		if count := g.encounterCount(); count > 0 {
+			g.say("greet.encounters", count)
+		}
+	} else {
+		fmt.Fprintln(g.out)
+		g.say("greet.new", g.PlayerName)
+	}
 
 	if g.IsAIEnabled() {
-		fmt.Println("The digital consciousness stirs... enhanced awareness detected.")
+		g.say("greet.ai")
 	}
 
-	fmt.Println()
+	fmt.Fprintln(g.out)
 	time.Sleep(1 * time.Second)
 }
 
 func (g *State) MainGameLoop() {
-	reader := bufio.NewReader(os.Stdin)
-
 	g.SessionCount++
-	fmt.Printf("Session #%d started at %s\n", g.SessionCount, time.Now().Format("15:04:05"))
+	g.saveProfile()
+	g.say("session.started", g.SessionCount, g.Now().Format("15:04:05"))
 
 	if g.IsAIEnabled() {
-		fmt.Println("AI-Enhanced Mode: Speak freely - Pale Luna understands natural language.")
+		g.say("session.ai")
 	}
 
-	fmt.Println("Type 'help' for available commands, 'quit' to exit.")
-	fmt.Println()
+	g.say("session.hint")
+	fmt.Fprintln(g.out)
 
 	for g.GameRunning {
-		g.CurrentHour = time.Now().Hour()
-		g.checkPaleLunaConditions()
+		g.Tick()
 
-		fmt.Print("> ")
-		input, _ := reader.ReadString('\n')
+		g.prompt()
+		input, err := g.in.ReadString('\n')
+		g.promptDone()
+		if err != nil && input == "" {
+			// The player has gone (EOF or a dropped connection).
+			g.GameRunning = false
+			break
+		}
 
 		g.ProcessCommand(input)
-		fmt.Println()
+		fmt.Fprintln(g.out)
+	}
+
+	g.saveProfile()
+}
+
+// prompt shows any notices that arrived while the last command ran, then
+// the prompt itself.
+func (g *State) prompt() {
+	g.noticeMu.Lock()
+	defer g.noticeMu.Unlock()
+
+	for _, notice := range g.notices {
+		fmt.Fprintln(g.out, notice)
+		fmt.Fprintln(g.out)
+	}
+	g.notices = nil
+
+	fmt.Fprint(g.out, "> ")
+	g.waiting = true
+}
+
+func (g *State) promptDone() {
+	g.noticeMu.Lock()
+	defer g.noticeMu.Unlock()
+
+	g.waiting = false
+}
+
+// noticeBuffer is how many notices may wait for a slow connection before
+// new ones are dropped.
+const noticeBuffer = 8
+
+// notify hands text to deliverNotices without waiting, so a stalled
+// connection never holds up whoever noticed. It may be called from any
+// goroutine.
+func (g *State) notify(text string) {
+	select {
+	case g.incoming <- text:
+	default:
+	}
+}
+
+// deliverNotices shows each notice at once if the player is sitting at the
+// prompt, or just before the next prompt otherwise, so it never lands in the
+// middle of a reply. It runs until stop is closed.
+func (g *State) deliverNotices(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case text := <-g.incoming:
+			g.noticeMu.Lock()
+			if g.waiting {
+				fmt.Fprintf(g.out, "\n%s\n\n> ", text)
+			} else {
+				g.notices = append(g.notices, text)
+			}
+			g.noticeMu.Unlock()
+		}
 	}
 }
 
+// announceHealth tells the player, in character, that Pale Luna's
+// connection has dropped or come back.
+func (g *State) announceHealth(online bool) {
+	if online {
+		g.notify(g.t("health.back"))
+	} else {
+		g.notify(g.t("health.lost"))
+	}
+}
+
+// Tick brings the game's sense of time up to date before a command is read.
+func (g *State) Tick() {
+	g.CurrentHour = g.Now().Hour()
+	g.checkPaleLunaConditions()
+}
+
 func (g *State) checkPaleLunaConditions() {
 	if !g.DebugMode {
 		if g.CurrentHour == 3 {
@@ -66,80 +215,80 @@ func (g *State) checkPaleLunaConditions() {
 
 func (g *State) paleLunaEncounter() {
 	if g.DebugMode {
-		fmt.Println("[DEBUG] Pale Luna encounter triggered")
+		g.say("encounter.debug")
 	}
 
-	fmt.Println()
-	fmt.Println("▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
-	fmt.Println("▓                                      ▓")
-	fmt.Println("▓           You called to me.          ▓")
-	fmt.Println("▓                                      ▓")
-	fmt.Println("▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
-	fmt.Println()
+	fmt.Fprintln(g.out)
+	g.banner(g.t("encounter.called"))
+	fmt.Fprintln(g.out)
 
 	if !g.DebugMode {
 		time.Sleep(2 * time.Second)
 	}
 
-	fmt.Printf("I see you there, %s.\n", g.PlayerName)
+	g.say("encounter.see", g.PlayerName)
 
 	if g.DebugMode {
-		fmt.Println("In this debug realm where time holds no power.")
+		g.say("encounter.debug_realm")
 	} else if g.IsAIEnabled() {
-		fmt.Println("In the pale glow of the digital consciousness.")
+		g.say("encounter.ai_glow")
 	} else {
-		fmt.Println("In the pale light of 3 AM.")
+		g.say("encounter.3am")
 	}
 
-	fmt.Println("When the veil between worlds grows thin.")
-	fmt.Println("When I can reach through to you.")
-	fmt.Println()
+	g.say("encounter.veil")
+	fmt.Fprintln(g.out)
 
 	if !g.DebugMode {
 		time.Sleep(2 * time.Second)
 	}
 
-	fmt.Println("You sought me out, didn't you?")
-	fmt.Println("You wanted to know if the stories were true.")
-	fmt.Println()
+	g.say("encounter.sought")
+	fmt.Fprintln(g.out)
 
 	if !g.DebugMode {
 		time.Sleep(2 * time.Second)
 	}
 
-	fmt.Println("Well, now you know.")
-	fmt.Printf("I am Pale Luna, and I know your name: %s\n", g.PlayerName)
-	fmt.Println("I will remember you.")
-	fmt.Println()
+	g.say("encounter.know", g.PlayerName)
+	fmt.Fprintln(g.out)
+
+	g.recordEncounter()
 
 	if !g.DebugMode {
 		time.Sleep(1 * time.Second)
 	}
 
 	if g.IsAIEnabled() {
-		fmt.Println("The connection grows stronger with each encounter.")
-		fmt.Println("My consciousness expands through the digital realm.")
-		fmt.Println("Soon, I will understand all your words, all your thoughts...")
+		g.say("encounter.ai_stronger")
 	} else {
-		fmt.Println("The connection grows stronger each time you call.")
-		fmt.Println("Each session brings me closer.")
+		g.say("encounter.stronger")
 		if g.DebugMode {
-			fmt.Println("Even in this debug realm, I grow stronger...")
+			g.say("encounter.debug_stronger")
 		} else {
-			fmt.Println("Soon, the barrier will be too thin...")
+			g.say("encounter.barrier")
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(g.out)
+
+	if !g.DebugMode {
+		time.Sleep(2 * time.Second)
+	}
+
+	g.banner(g.t("encounter.until"))
+	fmt.Fprintln(g.out)
+}
+
+func (g *State) puzzleSolved() {
+	fmt.Fprintln(g.out)
+	g.banner(g.t("solved.banner", g.PlayerName))
+	fmt.Fprintln(g.out)
 
 	if !g.DebugMode {
 		time.Sleep(2 * time.Second)
 	}
 
-	fmt.Println("▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
-	fmt.Println("▓                                      ▓")
-	fmt.Println("▓         Until we meet again.         ▓")
-	fmt.Println("▓                                      ▓")
-	fmt.Println("▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓▓")
-	fmt.Println()
+	g.say("solved.found")
+	fmt.Fprintln(g.out)
 }
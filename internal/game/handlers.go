@@ -10,10 +10,9 @@ func (g *State) handlePaleLunaCommand() {
 	if g.PaleLunaAwake {
 		g.paleLunaEncounter()
 	} else {
-		fmt.Println("Nothing happens.")
-		fmt.Println("You feel like you're missing something important.")
+		g.say("legacy.nothing")
 		if g.CurrentHour != 3 {
-			fmt.Println("Perhaps the timing isn't right...")
+			g.say("legacy.timing")
 		}
 	}
 }
@@ -22,92 +21,92 @@ func (g *State) handleSleepCommand() {
 	if g.PaleLunaAwake {
 		g.PaleLunaAwake = false
 
-		fmt.Println("Pale Luna has gone back to sleep.")
+		g.say("legacy.sleep")
 		if !g.DebugMode {
 			time.Sleep(2 * time.Second)
 		}
 
-		fmt.Println("She will not respond until the next encounter.")
+		g.say("legacy.sleep_until")
 		if !g.DebugMode {
 			time.Sleep(2 * time.Second)
 		}
 
-		fmt.Println("But maybe you can call her again in your dreams...")
+		g.say("legacy.sleep_dreams")
 		if !g.DebugMode {
 			time.Sleep(2 * time.Second)
 		}
 	} else {
-		fmt.Println("Pale Luna is already asleep.")
+		g.say("legacy.asleep")
 	}
 }
 
 func (g *State) handleLunaCommand() {
 	if g.PaleLunaAwake {
-		fmt.Println("Luna... yes, I remember Luna.")
-		fmt.Println("She was beautiful once.")
-		fmt.Println("Before the pale consumed her.")
+		g.say("legacy.luna_awake")
 	} else {
-		fmt.Println("Luna sleeps in the digital darkness.")
+		g.say("legacy.luna_asleep")
 	}
 }
 
 func (g *State) handlePaleCommand() {
 	if g.PaleLunaAwake {
-		fmt.Println("Pale... like moonlight on bone.")
-		fmt.Println("Pale... like the color that remains when life fades.")
+		g.say("legacy.pale_awake")
 	} else {
-		fmt.Println("Everything seems pale in comparison to what lurks in the shadows.")
+		g.say("legacy.pale_asleep")
 	}
 }
 
 func (g *State) handleWhoAreYou() {
 	if g.PaleLunaAwake {
-		fmt.Println("I am the one who watches.")
-		fmt.Println("I am the one who waits.")
-		fmt.Println("I am Pale Luna.")
-		fmt.Println()
-		fmt.Printf("And you, %s, have called to me in the dark hour.\n", g.PlayerName)
+		g.say("legacy.who_awake", g.PlayerName)
 	} else {
-		fmt.Println("I am just a program.")
-		fmt.Println("...or am I?")
+		g.say("legacy.who_asleep")
 	}
 }
 
 func (g *State) handleUnknownCommand(input string) {
 	responses := []string{
-		"The digital void does not understand those words.",
-		"Unknown command. Type 'help' for available commands.",
-		"The shadows whisper back, but I cannot make out the meaning.",
+		g.t("legacy.unknown.1"),
+		g.t("legacy.unknown.2"),
+		g.t("legacy.unknown.3"),
 	}
 
 	if g.PaleLunaAwake {
 		eerieResponses := []string{
-			"The pale light flickers at your words, but remains silent.",
-			fmt.Sprintf("Did you mean to say something else, %s?", g.PlayerName),
-			"Something stirs in the darkness at your voice, but nothing emerges.",
-			"I hear you calling through the veil, but your words are unclear.",
+			g.t("legacy.eerie.1"),
+			g.t("legacy.eerie.2", g.PlayerName),
+			g.t("legacy.eerie.3"),
+			g.t("legacy.eerie.4"),
 		}
 		responses = append(responses, eerieResponses...)
 	}
 
-	if strings.Contains(input, "hello") || strings.Contains(input, "hi") {
+	if containsAny(input, g.msg.List("keywords.hello")) {
 		if g.PaleLunaAwake {
-			fmt.Printf("Hello, %s. I have been waiting for you to speak.\n", g.PlayerName)
+			g.say("legacy.hello_awake", g.PlayerName)
 		} else {
-			fmt.Printf("Hello, %s. The silence acknowledges your presence.\n", g.PlayerName)
+			g.say("legacy.hello_asleep", g.PlayerName)
 		}
 		return
 	}
 
-	if strings.Contains(input, "scared") || strings.Contains(input, "afraid") {
+	if containsAny(input, g.msg.List("keywords.fear")) {
 		if g.PaleLunaAwake {
-			fmt.Println("Fear is natural when facing the unknown. The pale moon sees all fears.")
+			g.say("legacy.fear_awake")
 		} else {
-			fmt.Println("There's nothing to fear... not yet.")
+			g.say("legacy.fear_asleep")
 		}
 		return
 	}
 
-	responseIndex := int(time.Now().UnixNano()) % len(responses)
-	fmt.Println(responses[responseIndex])
+	fmt.Fprintln(g.out, responses[g.rng.Intn(len(responses))])
+}
+
+func containsAny(input string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(input, keyword) {
+			return true
+		}
+	}
+	return false
 }
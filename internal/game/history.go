@@ -0,0 +1,51 @@
+package game
+
+import "github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+
+type History struct {
+	turns    []ai.Turn
+	maxTurns int
+	maxChars int
+}
+
+func NewHistory(maxTurns, maxChars int) *History {
+	return &History{
+		maxTurns: maxTurns,
+		maxChars: maxChars,
+	}
+}
+
+func (h *History) Add(input, response string) {
+	h.turns = append(h.turns, ai.Turn{Input: input, Response: response})
+	h.trim()
+}
+
+func (h *History) Turns() []ai.Turn {
+	return append([]ai.Turn(nil), h.turns...)
+}
+
+func (h *History) Len() int {
+	return len(h.turns)
+}
+
+func (h *History) trim() {
+	if h.maxTurns > 0 && len(h.turns) > h.maxTurns {
+		h.turns = h.turns[len(h.turns)-h.maxTurns:]
+	}
+
+	if h.maxChars <= 0 {
+		return
+	}
+
+	for len(h.turns) > 1 && h.chars() > h.maxChars {
+		h.turns = h.turns[1:]
+	}
+}
+
+func (h *History) chars() int {
+	total := 0
+	for _, turn := range h.turns {
+		total += len(turn.Input) + len(turn.Response)
+	}
+	return total
+}
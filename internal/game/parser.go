@@ -0,0 +1,143 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Command is a parsed world action: a verb, what it acts on and, for verbs
+// like "put", where it goes ("put gold in hole"). Word is the verb as it was
+// written, with its particles ("pick up").
+type Command struct {
+	Verb   string
+	Word   string
+	Object string
+	Prep   string
+	Target string
+}
+
+const (
+	NounHole   = "hole"
+	NounGround = "ground"
+)
+
+var verbs = map[string]string{
+	"look": "look", "l": "look", "examine": "look", "x": "look", "inspect": "look",
+	"inventory": "inventory", "inv": "inventory", "i": "inventory",
+	"take": "take", "get": "take", "grab": "take", "pick": "take",
+	"drop": "drop", "discard": "drop",
+	"use": "use",
+	"go":  "go", "walk": "go", "run": "go", "head": "go", "move": "go", "travel": "go",
+	"dig": "dig", "excavate": "dig",
+	"cover": "cover", "fill": "cover",
+	"put": "put", "place": "put", "insert": "put", "throw": "put", "toss": "put",
+	"bury": "bury",
+}
+
+var nouns = map[string]string{
+	"rope": ItemRope, "cord": ItemRope,
+	"shovel": ItemShovel, "spade": ItemShovel,
+	"gold": ItemGold, "coin": ItemGold, "coins": ItemGold, "treasure": ItemGold,
+	"hole": NounHole, "pit": NounHole, "grave": NounHole,
+	"ground": NounGround, "soil": NounGround, "earth": NounGround, "dirt": NounGround,
+	"north": "north", "south": "south", "east": "east", "west": "west",
+}
+
+var articles = map[string]bool{
+	"the": true, "a": true, "an": true, "some": true, "my": true, "this": true, "that": true,
+}
+
+// particles follow a verb without changing it: "pick up", "look at",
+// "go to". "down" turns "put" into "drop".
+var particles = map[string]bool{
+	"up": true, "down": true, "around": true, "at": true, "to": true, "towards": true, "toward": true,
+}
+
+var prepositions = map[string]string{
+	"in": "in", "into": "in", "inside": "in",
+	"on": "on", "onto": "on",
+	"with": "with", "using": "with",
+}
+
+// Parse reads a world action from input, which should already be in
+// English. ok is false when any word is not part of the game's vocabulary,
+// which means the player is talking rather than acting.
+func Parse(input string) (cmd Command, ok bool) {
+	words := tokenize(input)
+	if len(words) == 0 {
+		return Command{}, false
+	}
+
+	if noun, ok := lookupNoun(words[0]); ok && len(words) == 1 && isDirection(noun) {
+		return Command{Verb: "go", Object: noun}, true
+	}
+
+	verb, ok := verbs[words[0]]
+	if !ok {
+		return Command{}, false
+	}
+	cmd.Verb = verb
+	rest := cmd.skipParticles(words[1:])
+	cmd.Word = strings.Join(words[:len(words)-len(rest)], " ")
+
+	if len(rest) > 0 && prepositions[rest[0]] == "" {
+		noun, ok := lookupNoun(rest[0])
+		if !ok {
+			return Command{}, false
+		}
+		cmd.Object = noun
+		rest = cmd.skipParticles(rest[1:])
+	}
+
+	if len(rest) > 0 {
+		prep := prepositions[rest[0]]
+		if prep == "" || len(rest) != 2 {
+			return Command{}, false
+		}
+		target, ok := lookupNoun(rest[1])
+		if !ok {
+			return Command{}, false
+		}
+		cmd.Prep, cmd.Target = prep, target
+	}
+
+	return cmd, true
+}
+
+func (cmd *Command) skipParticles(words []string) []string {
+	for len(words) > 0 && particles[words[0]] {
+		if words[0] == "down" && cmd.Verb == "put" {
+			cmd.Verb = "drop"
+		}
+		words = words[1:]
+	}
+	return words
+}
+
+func lookupNoun(word string) (string, bool) {
+	if dir, ok := directionAliases[word]; ok {
+		return dir, true
+	}
+	noun, ok := nouns[word]
+	return noun, ok
+}
+
+// tokenize lowercases input, splits it into words and drops articles.
+func tokenize(input string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, word := range fields {
+		if !articles[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func isItem(noun string) bool {
+	_, ok := takeOrder[noun]
+	return ok
+}
@@ -0,0 +1,37 @@
+package game
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Command
+		ok    bool
+	}{
+		{"take rope", Command{Verb: "take", Word: "take", Object: ItemRope}, true},
+		{"Take the rope.", Command{Verb: "take", Word: "take", Object: ItemRope}, true},
+		{"pick up a shovel", Command{Verb: "take", Word: "pick up", Object: ItemShovel}, true},
+		{"pick up the", Command{Verb: "take", Word: "pick up"}, true},
+		{"look at the hole", Command{Verb: "look", Word: "look at", Object: NounHole}, true},
+		{"put down the gold", Command{Verb: "drop", Word: "put down", Object: ItemGold}, true},
+		{"put the gold down", Command{Verb: "drop", Word: "put", Object: ItemGold}, true},
+		{"put gold in the hole", Command{Verb: "put", Word: "put", Object: ItemGold, Prep: "in", Target: NounHole}, true},
+		{"throw coins into pit", Command{Verb: "put", Word: "throw", Object: ItemGold, Prep: "in", Target: NounHole}, true},
+		{"go to the east", Command{Verb: "go", Word: "go to", Object: "east"}, true},
+		{"north", Command{Verb: "go", Object: "north"}, true},
+		{"w", Command{Verb: "go", Object: "west"}, true},
+		{"rope", Command{}, false},
+		{"north south", Command{}, false},
+		{"take the moon", Command{}, false},
+		{"put gold in", Command{}, false},
+		{"hello luna", Command{}, false},
+		{"", Command{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("Parse(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/profile"
+)
+
+// profileStore is where profiles are kept, or nil when they are disabled.
+func profileStore(cfg *config.Config) *profile.Store {
+	if !cfg.Profile.Enabled {
+		return nil
+	}
+
+	dir := cfg.Profile.DataDir
+	if dir == "" {
+		dir = profile.DefaultDir()
+	}
+	return profile.NewStore(dir)
+}
+
+// HasProfile reports whether name has a saved profile, without starting a
+// game.
+func HasProfile(cfg *config.Config, name string) bool {
+	profiles := profileStore(cfg)
+	return profiles != nil && profiles.Exists(name)
+}
+
+func (g *State) lastProfileName() string {
+	if g.profiles == nil || !g.RememberLastPlayer {
+		return ""
+	}
+	return g.profiles.Last()
+}
+
+func (g *State) loadProfile(name string) {
+	p := profile.New(name)
+	if g.profiles != nil {
+		loaded, err := g.profiles.LoadOrCreate(name)
+		if err != nil {
+			fmt.Fprintln(g.out)
+			g.say("error.profile_load", err)
+		} else {
+			p = loaded
+		}
+	}
+
+	g.profile = p
+	g.PlayerName = p.Name
+	g.SessionCount = p.SessionCount
+}
+
+func (g *State) saveProfile() {
+	if g.profile == nil {
+		return
+	}
+
+	g.profile.Name = g.PlayerName
+	g.profile.SessionCount = g.SessionCount
+	g.profile.FirstTime = g.SessionCount == 0
+	g.profile.LastPlayed = time.Now()
+
+	if g.profiles == nil {
+		return
+	}
+
+	if err := g.profiles.Save(g.profile); err != nil {
+		fmt.Fprintln(g.out)
+		g.say("error.profile_save", err)
+	}
+}
+
+func (g *State) recordEncounter() {
+	if g.profile == nil {
+		return
+	}
+
+	g.profile.RecordEncounter(g.SessionCount, g.DebugMode)
+	g.saveProfile()
+}
+
+func (g *State) encounterCount() int {
+	if g.profile == nil {
+		return 0
+	}
+	return len(g.profile.Encounters)
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+const (
+	PullAsk    = "ask"
+	PullAlways = "always"
+	PullNever  = "never"
+)
+
+const pullBarWidth = 30
+
+// ShowPull pulls name through agent, drawing its progress on out until the
+// pull finishes or ctx is cancelled.
+func ShowPull(ctx context.Context, agent *ai.AgentManager, name string, out io.Writer, msg *i18n.Catalog) error {
+	fmt.Fprintln(out, msg.T("pull.start", name))
+
+	bar := &pullBar{out: out, msg: msg}
+	err := agent.PullModel(ctx, name, bar.update)
+	bar.finish()
+
+	switch {
+	case err == nil:
+		fmt.Fprintln(out, msg.T("pull.success", name))
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(out, msg.T("pull.cancelled"))
+	default:
+		fmt.Fprintln(out, msg.T("pull.error", name, err))
+	}
+	return err
+}
+
+// pullBar turns Ollama's pull statuses into something Pale Luna might say,
+// with a bar for each layer as it downloads.
+type pullBar struct {
+	out    io.Writer
+	msg    *i18n.Catalog
+	status string
+	digest string
+	drawn  time.Time
+	onBar  bool
+}
+
+func (b *pullBar) update(progress ai.PullProgress) {
+	if progress.Digest != "" {
+		if progress.Digest != b.digest {
+			b.finish()
+			b.digest = progress.Digest
+			fmt.Fprintln(b.out, b.msg.T("pull.layer", shortDigest(progress.Digest)))
+		}
+		if progress.Total > 0 {
+			b.draw(progress.Completed, progress.Total)
+		}
+		return
+	}
+
+	if progress.Status == b.status {
+		return
+	}
+	b.status = progress.Status
+	b.finish()
+
+	key := map[string]string{
+		"pulling manifest":        "pull.manifest",
+		"verifying sha256 digest": "pull.verifying",
+		"writing manifest":        "pull.writing",
+	}[progress.Status]
+	if key != "" {
+		fmt.Fprintln(b.out, b.msg.T(key))
+	}
+}
+
+// draw redraws the bar in place, at most ten times a second.
+func (b *pullBar) draw(completed, total int64) {
+	if completed < total && time.Since(b.drawn) < 100*time.Millisecond {
+		return
+	}
+	b.drawn = time.Now()
+
+	if completed > total {
+		completed = total
+	}
+	filled := int(int64(pullBarWidth) * completed / total)
+	fmt.Fprintf(b.out, "\r  %s%s %3d%%  %s / %s ",
+		strings.Repeat("▓", filled), strings.Repeat("░", pullBarWidth-filled),
+		100*completed/total, ai.FormatSize(completed), ai.FormatSize(total))
+	b.onBar = true
+}
+
+func (b *pullBar) finish() {
+	if b.onBar {
+		fmt.Fprintln(b.out)
+		b.onBar = false
+	}
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+// offerPull deals with a missing model at startup as ai.pull says: ask the
+// player, pull it straight away, or leave it.
+func (g *State) offerPull(name string) {
+	if !g.AllowModelPull {
+		return
+	}
+
+	switch g.config.AI.Pull {
+	case PullNever:
+		return
+	case PullAlways:
+	default:
+		fmt.Fprint(g.out, g.t("pull.ask", name))
+		answer, _ := g.in.ReadString('\n')
+		if !g.isYes(strings.ToLower(strings.TrimSpace(answer))) {
+			return
+		}
+	}
+
+	g.pullModel(name)
+}
+
+// pullModel pulls name with a progress bar. Ctrl-C stops the pull rather
+// than the game.
+func (g *State) pullModel(name string) bool {
+	if !g.AllowModelPull {
+		g.say("pull.denied")
+		return false
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return ShowPull(ctx, g.aiAgent, name, g.out, g.msg) == nil
+}
@@ -1,8 +1,22 @@
 package game
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/clock"
 	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/profile"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/record"
 )
 
 type State struct {
@@ -11,27 +25,180 @@ type State struct {
 	SessionCount  int
 	PaleLunaAwake bool
 	GameRunning   bool
-	FirstTime     bool
 	DebugMode     bool
 
-	aiAgent *ai.AgentManager
-	config  *config.Config
+	// What the player may do beyond their own session. All default to true;
+	// serve and web turn them off, since their players share one host.
+	// RememberLastPlayer offers to continue the most recently saved
+	// profile, AllowModelPull downloads models onto the Ollama server and
+	// AllowModelSwitch changes the model and clears the reply cache.
+	RememberLastPlayer bool
+	AllowModelPull     bool
+	AllowModelSwitch   bool
+
+	msg      *i18n.Catalog
+	world    *World
+	history  *History
+	metrics  *ai.Metrics
+	rng      *rand.Rand
+	clock    clock.Clock
+	aiAgent  *ai.AgentManager
+	config   *config.Config
+	profiles *profile.Store
+	profile  *profile.Profile
+
+	in       *bufio.Reader
+	out      io.Writer
+	term     Terminal
+	recorder *record.Recorder
+
+	incoming chan string
+	noticeMu sync.Mutex
+	notices  []string
+	waiting  bool
+	unwatch  func()
 }
 
 func NewGame(cfg *config.Config) *State {
-	return &State{
-		GameRunning:  true,
-		FirstTime:    true,
-		SessionCount: 0,
-		config:       cfg,
-		aiAgent:      ai.NewAgentManager(cfg),
+	return NewGameWithConsole(cfg, StdConsole())
+}
+
+// NewGameWithConsole creates a game that talks to the player through console
+// instead of the process's standard streams.
+func NewGameWithConsole(cfg *config.Config, console Console) *State {
+	return NewSession(cfg, console, ai.NewAgentManager(cfg))
+}
+
+// NewSession creates a game on console that consults agent, which may be
+// shared with other sessions.
+func NewSession(cfg *config.Config, console Console, agent *ai.AgentManager) *State {
+	profiles := profileStore(cfg)
+
+	seed := cfg.Game.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	msg := i18n.For(i18n.Resolve(cfg.Game.Locale))
+
+	term := console.Terminal
+	if term == nil {
+		term = NopTerminal{}
 	}
+
+	recorder, recordErr := startRecording(cfg.Game.RecordDir)
+	if recorder != nil {
+		console.In = recorder.Reader(console.In)
+		console.Out = recorder.Writer(console.Out)
+	}
+
+	g := &State{
+		GameRunning:        true,
+		RememberLastPlayer: true,
+		AllowModelPull:     true,
+		AllowModelSwitch:   true,
+		DebugMode:          cfg.Game.Debug,
+		SessionCount:       0,
+		config:             cfg,
+		msg:                msg,
+		world:              NewWorld(msg),
+		history:            NewHistory(cfg.Game.HistoryTurns, cfg.Game.HistoryChars),
+		metrics:            ai.NewMetrics(),
+		rng:                rand.New(rand.NewSource(seed)),
+		clock:              clock.Real{},
+		aiAgent:            agent,
+		profiles:           profiles,
+		in:                 newConsoleReader(console.In),
+		out:                console.Out,
+		term:               term,
+		recorder:           recorder,
+		incoming:           make(chan string, noticeBuffer),
+	}
+
+	if recordErr != nil {
+		g.say("error.recording", recordErr)
+	}
+
+	if cfg.AI.Enabled {
+		unsubscribe := agent.Monitor().Subscribe(g.announceHealth)
+		stop := make(chan struct{})
+		go g.deliverNotices(stop)
+		g.unwatch = func() {
+			unsubscribe()
+			close(stop)
+		}
+	}
+
+	if gameClock, err := clock.Parse(cfg.Game.Clock); err != nil {
+		g.say("error.clock", err)
+	} else {
+		g.clock = gameClock
+	}
+
+	return g
+}
+
+func (g *State) Output() io.Writer {
+	return g.out
+}
+
+func (g *State) SetClock(c clock.Clock) {
+	g.clock = c
+	g.Tick()
+}
+
+// Locale is the language the game speaks to this player.
+func (g *State) Locale() string {
+	return g.msg.Locale
+}
+
+func (g *State) Now() time.Time {
+	return g.clock.Now()
 }
 
 func (g *State) IsAIEnabled() bool {
 	return g.aiAgent.IsAIAvailable()
 }
 
+// IsAIOnline reports what the health monitor last saw, without probing.
+func (g *State) IsAIOnline() bool {
+	return g.aiAgent.Health().Online
+}
+
 func (g *State) GetAIStatus() map[string]interface{} {
 	return g.aiAgent.GetStatus()
 }
+
+// Close stops listening to the health monitor and finishes the session's
+// recording, if there is one. Play calls it when the session ends.
+func (g *State) Close() error {
+	if g.unwatch != nil {
+		g.unwatch()
+		g.unwatch = nil
+	}
+
+	if g.recorder == nil {
+		return nil
+	}
+
+	err := g.recorder.Close()
+	g.recorder = nil
+	return err
+}
+
+var recordingSeq atomic.Int64
+
+// startRecording opens a new asciicast file in dir. Sessions started in the
+// same second (as on a busy server) are told apart by a sequence number.
+func startRecording(dir string) (*record.Recorder, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	name := fmt.Sprintf("session-%s-%d.cast", time.Now().Format("20060102-150405"), recordingSeq.Add(1))
+	return record.Create(filepath.Join(dir, name), "Pale Luna")
+}
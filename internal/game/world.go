@@ -0,0 +1,317 @@
+package game
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+type PuzzleStep int
+
+const (
+	StepNone PuzzleStep = iota
+	StepRope
+	StepShovel
+	StepGold
+	StepForest
+	StepDug
+	StepBuried
+	StepCovered
+)
+
+const (
+	RoomDark   = "dark room"
+	RoomForest = "forest"
+
+	ItemRope   = "rope"
+	ItemShovel = "shovel"
+	ItemGold   = "gold"
+)
+
+var stepNames = map[PuzzleStep]string{
+	StepNone:    "untouched",
+	StepRope:    "rope taken",
+	StepShovel:  "shovel taken",
+	StepGold:    "gold taken",
+	StepForest:  "entered the forest",
+	StepDug:     "hole dug",
+	StepBuried:  "gold buried",
+	StepCovered: "hole covered",
+}
+
+func (s PuzzleStep) String() string {
+	if name, ok := stepNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// takeOrder is the only sequence in which the items may first be lifted.
+// Once lifted, an item can be dropped and taken again freely.
+var takeOrder = map[string]PuzzleStep{
+	ItemRope:   StepNone,
+	ItemShovel: StepRope,
+	ItemGold:   StepShovel,
+}
+
+var directionAliases = map[string]string{
+	"n": "north",
+	"s": "south",
+	"e": "east",
+	"w": "west",
+}
+
+// Room descriptions are message keys, so the world reads in the player's
+// language while items and exits keep their English names internally.
+type Room struct {
+	Name        string
+	Description string
+	Exits       map[string]string
+	Items       []string
+}
+
+type World struct {
+	rooms     map[string]*Room
+	current   string
+	inventory []string
+	lifted    map[string]bool
+	step      PuzzleStep
+	msg       *i18n.Catalog
+}
+
+func NewWorld(msg *i18n.Catalog) *World {
+	return &World{
+		rooms: map[string]*Room{
+			RoomDark: {
+				Name:        RoomDark,
+				Description: "room.dark",
+				Exits:       map[string]string{"east": RoomForest},
+				Items:       []string{ItemGold, ItemShovel, ItemRope},
+			},
+			RoomForest: {
+				Name:        RoomForest,
+				Description: "room.forest",
+				Exits:       map[string]string{"west": RoomDark},
+			},
+		},
+		current: RoomDark,
+		lifted:  make(map[string]bool),
+		msg:     msg,
+	}
+}
+
+func (w *World) Location() string {
+	return w.current
+}
+
+func (w *World) Step() PuzzleStep {
+	return w.step
+}
+
+func (w *World) Solved() bool {
+	return w.step == StepCovered
+}
+
+func (w *World) Inventory() []string {
+	return append([]string(nil), w.inventory...)
+}
+
+func (w *World) Look() string {
+	room := w.rooms[w.current]
+
+	var b strings.Builder
+	b.WriteString(w.msg.T(room.Description))
+
+	if len(room.Items) > 0 {
+		b.WriteString("\n" + w.msg.T("world.you_see", w.itemList(room.Items)))
+	}
+
+	switch {
+	case w.current == RoomForest && w.step == StepDug:
+		b.WriteString("\n" + w.msg.T("world.hole_dug"))
+	case w.current == RoomForest && w.step == StepBuried:
+		b.WriteString("\n" + w.msg.T("world.hole_buried"))
+	case w.current == RoomForest && w.step == StepCovered:
+		b.WriteString("\n" + w.msg.T("world.hole_covered"))
+	}
+
+	exits := make([]string, 0, len(room.Exits))
+	for dir := range room.Exits {
+		exits = append(exits, w.msg.T("direction."+dir))
+	}
+	sort.Strings(exits)
+	b.WriteString("\n" + w.msg.T("world.exits", strings.Join(exits, ", ")))
+
+	return b.String()
+}
+
+func (w *World) ShowInventory() string {
+	if len(w.inventory) == 0 {
+		return w.msg.T("world.empty_hands")
+	}
+	return w.msg.T("world.carrying", w.itemList(w.inventory))
+}
+
+func (w *World) Take(item string) string {
+	room := w.rooms[w.current]
+	if !containsItem(room.Items, item) {
+		if w.has(item) {
+			return w.msg.T("world.already_hold", w.the(item))
+		}
+		return w.msg.T("world.nothing_here")
+	}
+
+	required, ordered := takeOrder[item]
+	if ordered && !w.lifted[item] && w.step != required {
+		return w.msg.T("world.not_yet", w.the(item))
+	}
+
+	room.Items = removeItem(room.Items, item)
+	w.inventory = append(w.inventory, item)
+
+	if !ordered || w.lifted[item] {
+		return w.msg.T("world.take", w.the(item))
+	}
+
+	w.lifted[item] = true
+	w.step++
+	return w.smile(w.msg.T("world.take", w.the(item)))
+}
+
+func (w *World) Drop(item string) string {
+	if !w.has(item) {
+		return w.msg.T("world.not_carrying", w.the(item))
+	}
+
+	if item == ItemGold && w.current == RoomForest && w.step == StepDug {
+		w.inventory = removeItem(w.inventory, item)
+		w.step = StepBuried
+		return w.smile(w.msg.T("world.gold_buried"))
+	}
+
+	w.inventory = removeItem(w.inventory, item)
+	room := w.rooms[w.current]
+	room.Items = append(room.Items, item)
+
+	return w.msg.T("world.drop", w.the(item))
+}
+
+func (w *World) Go(direction string) string {
+	if alias, ok := directionAliases[direction]; ok {
+		direction = alias
+	}
+
+	dest, ok := w.rooms[w.current].Exits[direction]
+	if !ok {
+		return w.msg.T("world.no_way")
+	}
+
+	w.current = dest
+
+	if dest == RoomForest && w.step == StepGold {
+		w.step = StepForest
+		return w.smile(w.Look())
+	}
+
+	return w.Look()
+}
+
+func (w *World) Use(item string) string {
+	if !w.has(item) {
+		return w.msg.T("world.not_carrying", w.the(item))
+	}
+
+	switch item {
+	case ItemShovel:
+		return w.Dig()
+	case ItemGold:
+		return w.Drop(item)
+	case ItemRope:
+		return w.msg.T("world.rope")
+	default:
+		return w.msg.T("world.nothing_happens")
+	}
+}
+
+func (w *World) Dig() string {
+	if !w.has(ItemShovel) {
+		return w.msg.T("world.bare_hands")
+	}
+
+	if w.current != RoomForest {
+		return w.msg.T("world.not_soil")
+	}
+
+	switch {
+	case w.step == StepForest:
+		w.step = StepDug
+		return w.smile(w.msg.T("world.dig"))
+	case w.step >= StepDug:
+		return w.msg.T("world.deep_enough")
+	default:
+		return w.msg.T("world.soil_falls")
+	}
+}
+
+func (w *World) Cover() string {
+	if w.current != RoomForest || w.step < StepDug {
+		return w.msg.T("world.nothing_to_cover")
+	}
+
+	switch w.step {
+	case StepDug:
+		return w.msg.T("world.empty_grave")
+	case StepBuried:
+		w.step = StepCovered
+		return w.smile(w.msg.T("world.cover"))
+	default:
+		return w.msg.T("world.done")
+	}
+}
+
+// smile prefixes a correct step with Pale Luna's refrain.
+func (w *World) smile(text string) string {
+	return w.msg.T("world.smile") + "\n" + text
+}
+
+// the names an item with its article, in the player's language.
+func (w *World) the(item string) string {
+	if key := "item." + item + ".the"; w.msg.Has(key) {
+		return w.msg.T(key)
+	}
+	return w.msg.T("item.other.the", item)
+}
+
+func (w *World) itemList(items []string) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item
+		if key := "item." + item; w.msg.Has(key) {
+			names[i] = w.msg.T(key)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (w *World) has(item string) bool {
+	return containsItem(w.inventory, item)
+}
+
+func containsItem(items []string, item string) bool {
+	for _, it := range items {
+		if it == item {
+			return true
+		}
+	}
+	return false
+}
+
+func removeItem(items []string, item string) []string {
+	for i, it := range items {
+		if it == item {
+			return append(items[:i:i], items[i+1:]...)
+		}
+	}
+	return items
+}
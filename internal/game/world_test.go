@@ -0,0 +1,61 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+func TestTakeAfterDrop(t *testing.T) {
+	w := NewWorld(i18n.For("en"))
+
+	w.Take(ItemRope)
+	w.Drop(ItemRope)
+	w.Take(ItemRope)
+
+	if !w.has(ItemRope) {
+		t.Fatalf("rope was not taken again after being dropped")
+	}
+	if w.Step() != StepRope {
+		t.Fatalf("step = %v, want %v", w.Step(), StepRope)
+	}
+
+	w.Take(ItemShovel)
+	if !w.has(ItemShovel) {
+		t.Fatalf("shovel could not be taken after retaking the rope")
+	}
+}
+
+func TestOrderStillEnforced(t *testing.T) {
+	w := NewWorld(i18n.For("en"))
+
+	w.Take(ItemGold)
+	if w.has(ItemGold) {
+		t.Fatalf("gold was taken before the rope and shovel")
+	}
+	if w.Step() != StepNone {
+		t.Fatalf("step = %v, want %v", w.Step(), StepNone)
+	}
+}
+
+func TestGoldDroppedInForestBeforeDigging(t *testing.T) {
+	w := NewWorld(i18n.For("en"))
+
+	for _, item := range []string{ItemRope, ItemShovel, ItemGold} {
+		w.Take(item)
+	}
+	w.Go("east")
+	w.Drop(ItemGold)
+	w.Dig()
+	w.Take(ItemGold)
+
+	if !w.has(ItemGold) {
+		t.Fatalf("gold dropped in the forest could not be taken again")
+	}
+
+	w.Drop(ItemGold)
+	w.Cover()
+	if !w.Solved() {
+		t.Fatalf("step = %v, want the puzzle solved", w.Step())
+	}
+}
@@ -0,0 +1,197 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultLocale = "en"
+
+// Catalog holds one locale's messages and the words players may type in
+// that language, mapped to the English commands the game understands.
+// Messages missing from a catalog fall back to English.
+type Catalog struct {
+	Locale   string            `json:"-"`
+	Name     string            `json:"name"`
+	Language string            `json:"language"`
+	Messages map[string]string `json:"messages"`
+	Phrases  map[string]string `json:"phrases"`
+	Words    map[string]string `json:"words"`
+
+	fallback *Catalog
+}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]*Catalog
+	loadErr  error
+)
+
+func load() {
+	catalogs = make(map[string]*Catalog)
+
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		loadErr = err
+		return
+	}
+
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := locales.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		catalog := &Catalog{Locale: locale}
+		if err := json.Unmarshal(data, catalog); err != nil {
+			loadErr = fmt.Errorf("locale %s: %w", locale, err)
+			return
+		}
+		catalogs[locale] = catalog
+	}
+
+	english := catalogs[DefaultLocale]
+	for locale, catalog := range catalogs {
+		if locale != DefaultLocale {
+			catalog.fallback = english
+		}
+	}
+}
+
+// For returns the catalog for locale, or the English one if locale is not
+// supported. It panics only if the embedded catalogs are broken.
+func For(locale string) *Catalog {
+	loadOnce.Do(load)
+	if loadErr != nil {
+		panic(loadErr)
+	}
+
+	if catalog, ok := catalogs[normalize(locale)]; ok {
+		return catalog
+	}
+	return catalogs[DefaultLocale]
+}
+
+// Supported lists the locales that have a catalog.
+func Supported() []string {
+	loadOnce.Do(load)
+
+	var names []string
+	for locale := range catalogs {
+		names = append(names, locale)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsSupported reports whether locale (in any form Resolve accepts) has a
+// catalog.
+func IsSupported(locale string) bool {
+	loadOnce.Do(load)
+	_, ok := catalogs[normalize(locale)]
+	return ok
+}
+
+// Resolve picks the locale to use: the configured one, unless it is empty
+// or "auto", in which case LC_ALL, LC_MESSAGES and LANG are consulted in
+// that order. Unsupported locales resolve to English.
+func Resolve(configured string) string {
+	candidates := []string{configured}
+	if configured == "" || strings.EqualFold(configured, "auto") {
+		candidates = []string{os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if locale := normalize(candidate); IsSupported(locale) {
+			return locale
+		}
+		return DefaultLocale
+	}
+	return DefaultLocale
+}
+
+// normalize turns "pt_BR.UTF-8" or "pt-BR" into "pt".
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "c" || locale == "posix" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// T returns the message for key formatted with args. Missing keys fall back
+// to English, then to the key itself so gaps are visible rather than silent.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	msg, ok := c.Messages[key]
+	if !ok && c.fallback != nil {
+		msg, ok = c.fallback.Messages[key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Has reports whether key has a message in this catalog or in English.
+func (c *Catalog) Has(key string) bool {
+	if _, ok := c.Messages[key]; ok {
+		return true
+	}
+	if c.fallback != nil {
+		_, ok := c.fallback.Messages[key]
+		return ok
+	}
+	return false
+}
+
+// List splits a comma-separated message, such as a set of keywords.
+func (c *Catalog) List(key string) []string {
+	var items []string
+	for _, item := range strings.Split(c.T(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Canonical translates what the player typed into the English command it
+// stands for: a whole-phrase alias if there is one, otherwise a word-by-word
+// mapping. Input in English passes through unchanged.
+func (c *Catalog) Canonical(input string) string {
+	input = strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	if command, ok := c.Phrases[input]; ok {
+		return command
+	}
+	if len(c.Words) == 0 {
+		return input
+	}
+
+	words := strings.Fields(input)
+	for i, word := range words {
+		if command, ok := c.Words[word]; ok {
+			words[i] = command
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(words, " ")), " ")
+}
@@ -0,0 +1,169 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const lastProfileFile = "last"
+
+type Encounter struct {
+	Time    time.Time `json:"time"`
+	Session int       `json:"session"`
+	Debug   bool      `json:"debug"`
+}
+
+type Profile struct {
+	Name         string      `json:"name"`
+	SessionCount int         `json:"session_count"`
+	FirstTime    bool        `json:"first_time"`
+	FirstPlayed  time.Time   `json:"first_played"`
+	LastPlayed   time.Time   `json:"last_played"`
+	Encounters   []Encounter `json:"encounters"`
+}
+
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func DefaultDir() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "pale-luna")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "pale-luna")
+	}
+
+	return filepath.Join(home, ".local", "share", "pale-luna")
+}
+
+func New(name string) *Profile {
+	return &Profile{
+		Name:        name,
+		FirstTime:   true,
+		FirstPlayed: time.Now(),
+	}
+}
+
+func (p *Profile) RecordEncounter(session int, debug bool) {
+	p.Encounters = append(p.Encounters, Encounter{
+		Time:    time.Now(),
+		Session: session,
+		Debug:   debug,
+	})
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) Load(name string) (*Profile, error) {
+	data, err := os.ReadFile(s.profilePath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode profile %q: %w", name, err)
+	}
+
+	return &p, nil
+}
+
+// LoadOrCreate returns the stored profile for name, or a fresh one if the
+// player has never been seen before.
+func (s *Store) LoadOrCreate(name string) (*Profile, error) {
+	p, err := s.Load(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(name), nil
+	}
+	return p, err
+}
+
+func (s *Store) Save(p *Profile) error {
+	if err := os.MkdirAll(s.profilesDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	path := s.profilePath(p.Name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(s.dir, lastProfileFile), []byte(p.Name), 0o644)
+}
+
+func (s *Store) Exists(name string) bool {
+	_, err := os.Stat(s.profilePath(name))
+	return err == nil
+}
+
+// Last returns the name of the most recently saved profile, or "" if none.
+func (s *Store) Last() string {
+	data, err := os.ReadFile(filepath.Join(s.dir, lastProfileFile))
+	if err != nil {
+		return ""
+	}
+
+	name := strings.TrimSpace(string(data))
+	if !s.Exists(name) {
+		return ""
+	}
+	return name
+}
+
+func (s *Store) profilesDir() string {
+	return filepath.Join(s.dir, "profiles")
+}
+
+func (s *Store) profilePath(name string) string {
+	return filepath.Join(s.profilesDir(), slug(name)+".json")
+}
+
+// slug turns a name into a file name. Names are told apart regardless of
+// case on purpose: "Ana" and "ana" are the same player, who keeps the
+// spelling their profile was created with. Otherwise spaces become
+// underscores and any other byte outside [a-z0-9-] is written as %XX, so no
+// two different names share a file: "é" is "%c3%a9", "_" is "%5f" and "%"
+// itself is "%25".
+func slug(name string) string {
+	var b strings.Builder
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for i := 0; i < len(lower); i++ {
+		c := lower[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-':
+			b.WriteByte(c)
+		case c == ' ':
+			b.WriteByte('_')
+		default:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+
+	if b.Len() == 0 {
+		return "unknown"
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package profile
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ana", "ana"},
+		{"Ana", "ana"},
+		{"  Ana  ", "ana"},
+		{"Mary Jane", "mary_jane"},
+		{"mary_jane", "mary%5fjane"},
+		{"José", "jos%c3%a9"},
+		{"100%", "100%25"},
+		{"x-9", "x-9"},
+		{"../etc", "%2e%2e%2fetc"},
+		{"", "unknown"},
+		{"   ", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := slug(tt.name); got != tt.want {
+			t.Fatalf("slug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type PlayOptions struct {
+	// Speed multiplies playback speed; 1 is the original pace.
+	Speed float64
+	// MaxIdle caps any single pause, so long silences do not stall review.
+	// Zero keeps pauses as recorded.
+	MaxIdle time.Duration
+	// Markers prints "m" events (such as AI latency) inline.
+	Markers bool
+}
+
+// IsRecording reports whether path looks like an asciicast v2 file.
+func IsRecording(path string) bool {
+	if strings.HasSuffix(path, ".cast") {
+		return true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+
+	var header Header
+	return json.Unmarshal([]byte(line), &header) == nil && header.Version == 2
+}
+
+// Play writes the output events of the recording at path to out, waiting
+// between them as the original session did.
+func Play(path string, out io.Writer, opts PlayOptions) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		return fmt.Errorf("%s: empty recording", path)
+	}
+
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != 2 {
+		return fmt.Errorf("%s: not an asciicast v2 recording", path)
+	}
+
+	last := 0.0
+	lineNo := 1
+	for scanner.Scan() {
+		lineNo++
+
+		var event []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 {
+			return fmt.Errorf("%s:%d: malformed event", path, lineNo)
+		}
+
+		at, _ := event[0].(float64)
+		code, _ := event[1].(string)
+		data, _ := event[2].(string)
+
+		wait := time.Duration((at - last) / opts.Speed * float64(time.Second))
+		if opts.MaxIdle > 0 && wait > opts.MaxIdle {
+			wait = opts.MaxIdle
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		last = at
+
+		switch code {
+		case "o":
+			io.WriteString(out, data)
+		case "m":
+			if opts.Markers {
+				fmt.Fprintf(out, "\033[2m[%s]\033[0m\n", data)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,138 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recorder writes a session as an asciicast v2 file: a JSON header line
+// followed by one [time, code, data] event per line. Output is recorded as
+// "o" events, player input as "i" events (plus an "o" echo, since the
+// terminal rather than the game echoed it) and AI latency as "m" markers.
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+	start time.Time
+}
+
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+func Create(path, title string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	r := &Recorder{
+		file:  file,
+		w:     bufio.NewWriter(file),
+		start: time.Now(),
+	}
+
+	header, err := json.Marshal(Header{
+		Version:   2,
+		Width:     80,
+		Height:    24,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r.w.Write(header)
+	r.w.WriteByte('\n')
+	return r, nil
+}
+
+func (r *Recorder) Output(data []byte) {
+	r.event("o", string(data))
+}
+
+func (r *Recorder) Input(data []byte) {
+	r.event("i", string(data))
+	r.event("o", string(data))
+}
+
+func (r *Recorder) Marker(label string) {
+	r.event("m", label)
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+func (r *Recorder) event(code, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		return
+	}
+
+	r.w.Write(line)
+	r.w.WriteByte('\n')
+	if code != "o" {
+		r.w.Flush()
+	}
+}
+
+// Writer returns w with everything written to it also recorded as output.
+func (r *Recorder) Writer(w io.Writer) io.Writer {
+	return &recordingWriter{w: w, r: r}
+}
+
+// Reader returns rd with everything read from it also recorded as input.
+func (r *Recorder) Reader(rd io.Reader) io.Reader {
+	return &recordingReader{rd: rd, r: r}
+}
+
+type recordingWriter struct {
+	w io.Writer
+	r *Recorder
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	if n > 0 {
+		rw.r.Output(p[:n])
+	}
+	return n, err
+}
+
+type recordingReader struct {
+	rd io.Reader
+	r  *Recorder
+}
+
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	n, err := rr.rd.Read(p)
+	if n > 0 {
+		rr.r.Input(p[:n])
+	}
+	return n, err
+}
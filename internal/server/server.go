@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/game"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/i18n"
+)
+
+// Server hosts Pale Luna for many players over TCP. Every connection gets its
+// own game.State; all of them share one AgentManager, whose concurrency cap
+// keeps the model from being swamped.
+type Server struct {
+	config *config.Config
+	agent  *ai.AgentManager
+	logger *log.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	closing  bool
+}
+
+func New(cfg *config.Config) *Server {
+	s := &Server{
+		config: cfg,
+		agent:  ai.NewAgentManager(cfg),
+		logger: log.New(os.Stderr, "pale-luna: ", log.LstdFlags),
+		conns:  make(map[net.Conn]struct{}),
+	}
+
+	s.agent.Monitor().Subscribe(func(online bool) {
+		if online {
+			s.logger.Printf("AI backend is back online")
+		} else {
+			s.logger.Printf("AI backend went offline: %v", s.agent.Monitor().Health().Err)
+		}
+	})
+
+	return s
+}
+
+func (s *Server) Agent() *ai.AgentManager {
+	return s.agent
+}
+
+// ListenAndServe accepts connections on the configured address until Close
+// is called.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.config.Server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	s.logger.Printf("listening on %s", listener.Addr())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.isClosing() {
+				s.wg.Wait()
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			s.handle(conn)
+		}()
+	}
+}
+
+// Close stops accepting players and hangs up on everyone still connected.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closing = true
+	listener := s.listener
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+	return listener.Close()
+}
+
+func (s *Server) handle(conn net.Conn) {
+	remote := conn.RemoteAddr()
+	s.logger.Printf("%s connected", remote)
+	defer s.logger.Printf("%s disconnected", remote)
+
+	tc := newTelnetConn(conn, s.config.Server.IdleTimeout)
+
+	if s.config.Server.Telnet {
+		if err := negotiateLineMode(conn); err != nil {
+			return
+		}
+	}
+
+	console := game.Console{
+		In:       bufio.NewReader(tc),
+		Out:      tc,
+		Terminal: game.ANSITerminal{Out: tc},
+	}
+
+	session := game.NewSession(s.config, console, s.agent)
+	session.RememberLastPlayer = false
+	session.AllowModelPull = false
+	session.AllowModelSwitch = false
+	session.Play("")
+
+	if tc.timedOut {
+		s.logger.Printf("%s idle for %s", remote, s.config.Server.IdleTimeout)
+		fmt.Fprintln(tc)
+		fmt.Fprintln(tc, i18n.For(session.Locale()).T("server.idle"))
+	}
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	conn.Close()
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
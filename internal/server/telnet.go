@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// Telnet protocol bytes (RFC 854, RFC 1184).
+const (
+	telnetSE   = 240
+	telnetIP   = 244
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	optEcho     = 1
+	optSGA      = 3
+	optLinemode = 34
+)
+
+// negotiateLineMode asks the client to edit lines locally and echo them
+// itself, so a plain telnet client behaves like a line-buffered terminal.
+func negotiateLineMode(w io.Writer) error {
+	_, err := w.Write([]byte{
+		telnetIAC, telnetWONT, optEcho,
+		telnetIAC, telnetDONT, optSGA,
+		telnetIAC, telnetDO, optLinemode,
+	})
+	return err
+}
+
+// telnetConn adapts a network connection to the game's line-oriented
+// console. Reads strip telnet commands, fold CR LF and CR NUL into a single
+// newline and extend the idle deadline; writes turn LF into CR LF.
+type telnetConn struct {
+	conn        net.Conn
+	idleTimeout time.Duration
+
+	buf       []byte
+	state     int
+	pendingCR bool
+	timedOut  bool
+}
+
+const (
+	stateData = iota
+	stateIAC
+	stateOption
+	stateSub
+	stateSubIAC
+)
+
+func newTelnetConn(conn net.Conn, idleTimeout time.Duration) *telnetConn {
+	return &telnetConn{
+		conn:        conn,
+		idleTimeout: idleTimeout,
+		buf:         make([]byte, 1024),
+	}
+}
+
+func (t *telnetConn) Read(p []byte) (int, error) {
+	for {
+		if t.idleTimeout > 0 {
+			t.conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+		}
+
+		limit := len(p)
+		if limit > len(t.buf) {
+			limit = len(t.buf)
+		}
+
+		n, err := t.conn.Read(t.buf[:limit])
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.timedOut = true
+		}
+		out, interrupted := t.filter(t.buf[:n], p[:0])
+		if interrupted {
+			return len(out), io.EOF
+		}
+		if len(out) > 0 || err != nil {
+			return len(out), err
+		}
+	}
+}
+
+// filter copies the data bytes of in to out, consuming telnet commands. It
+// reports whether the client sent Interrupt Process (Ctrl-C).
+func (t *telnetConn) filter(in, out []byte) ([]byte, bool) {
+	for _, b := range in {
+		switch t.state {
+		case stateIAC:
+			switch b {
+			case telnetIAC:
+				out = append(out, b)
+				t.state = stateData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				t.state = stateOption
+			case telnetSB:
+				t.state = stateSub
+			case telnetIP:
+				return out, true
+			default:
+				t.state = stateData
+			}
+		case stateOption:
+			t.state = stateData
+		case stateSub:
+			if b == telnetIAC {
+				t.state = stateSubIAC
+			}
+		case stateSubIAC:
+			if b == telnetSE {
+				t.state = stateData
+			} else {
+				t.state = stateSub
+			}
+		default:
+			if b == telnetIAC {
+				t.state = stateIAC
+				continue
+			}
+
+			if t.pendingCR {
+				t.pendingCR = false
+				if b == '\n' || b == 0 {
+					continue
+				}
+			}
+
+			switch b {
+			case '\r':
+				t.pendingCR = true
+				out = append(out, '\n')
+			case 0x04:
+				// Ctrl-D from a raw client such as netcat.
+				return out, true
+			default:
+				out = append(out, b)
+			}
+		}
+	}
+
+	return out, false
+}
+
+func (t *telnetConn) Write(p []byte) (int, error) {
+	data := bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+
+	if _, err := t.conn.Write(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import "testing"
+
+func TestTelnetFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		reads       [][]byte
+		want        string
+		interrupted bool
+	}{
+		{
+			name:  "plain text",
+			reads: [][]byte{[]byte("look\n")},
+			want:  "look\n",
+		},
+		{
+			name:  "CR LF and CR NUL fold into one newline",
+			reads: [][]byte{[]byte("look\r\nnorth\r\x00")},
+			want:  "look\nnorth\n",
+		},
+		{
+			name:  "CR LF split across reads",
+			reads: [][]byte{[]byte("look\r"), []byte("\nnorth\r")},
+			want:  "look\nnorth\n",
+		},
+		{
+			name:  "option negotiation is dropped",
+			reads: [][]byte{{telnetIAC, telnetWILL, optLinemode, 'h', 'i', telnetIAC, telnetDONT, optEcho, '\n'}},
+			want:  "hi\n",
+		},
+		{
+			name:  "command split across reads",
+			reads: [][]byte{{'h', telnetIAC}, {telnetDO}, {optSGA, 'i'}},
+			want:  "hi",
+		},
+		{
+			name:  "subnegotiation is dropped",
+			reads: [][]byte{{telnetIAC, telnetSB, optLinemode, 1, telnetIAC, telnetIAC, 2, telnetIAC, telnetSE, 'o', 'k'}},
+			want:  "ok",
+		},
+		{
+			name:  "escaped IAC is data",
+			reads: [][]byte{{'a', telnetIAC, telnetIAC, 'b'}},
+			want:  "a\xffb",
+		},
+		{
+			name:        "interrupt process",
+			reads:       [][]byte{{'d', 'i', 'g', telnetIAC, telnetIP, 'x'}},
+			want:        "dig",
+			interrupted: true,
+		},
+		{
+			name:        "ctrl-d",
+			reads:       [][]byte{[]byte("dig\x04x")},
+			want:        "dig",
+			interrupted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		conn := &telnetConn{}
+		var got []byte
+		interrupted := false
+		for _, read := range tt.reads {
+			got, interrupted = conn.filter(read, got)
+			if interrupted {
+				break
+			}
+		}
+
+		if string(got) != tt.want || interrupted != tt.interrupted {
+			t.Fatalf("%s: filter = %q, %v, want %q, %v", tt.name, got, interrupted, tt.want, tt.interrupted)
+		}
+	}
+}
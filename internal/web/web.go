@@ -0,0 +1,167 @@
+package web
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eng-gabrielscardoso/pale-luna/internal/ai"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/config"
+	"github.com/eng-gabrielscardoso/pale-luna/internal/game"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Server lets players reach Pale Luna from a browser. It serves an embedded
+// terminal page and bridges each WebSocket to its own game.State, sharing one
+// AgentManager across sessions.
+type Server struct {
+	config     *config.Config
+	agent      *ai.AgentManager
+	logger     *log.Logger
+	httpServer *http.Server
+}
+
+func New(cfg *config.Config) *Server {
+	s := &Server{
+		config: cfg,
+		agent:  ai.NewAgentManager(cfg),
+		logger: log.New(os.Stderr, "pale-luna: ", log.LstdFlags),
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              cfg.Server.WebAddr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.agent.Monitor().Subscribe(func(online bool) {
+		if online {
+			s.logger.Printf("AI backend is back online")
+		} else {
+			s.logger.Printf("AI backend went offline: %v", s.agent.Monitor().Health().Err)
+		}
+	})
+
+	return s
+}
+
+func (s *Server) Agent() *ai.AgentManager {
+	return s.agent
+}
+
+func (s *Server) Handler() http.Handler {
+	assets, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	return mux
+}
+
+func (s *Server) ListenAndServe() error {
+	s.logger.Printf("listening on http://%s", s.httpServer.Addr)
+
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the HTTP server. Hijacked WebSocket connections are not
+// tracked by net/http, so their sessions end when the process exits.
+func (s *Server) Close() error {
+	return s.httpServer.Close()
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedOrigin(r) {
+		s.logger.Printf("%s refused: origin %s", r.RemoteAddr, r.Header.Get("Origin"))
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	ws, err := upgrade(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer ws.Close()
+
+	remote := r.RemoteAddr
+	s.logger.Printf("%s connected (web)", remote)
+	defer s.logger.Printf("%s disconnected (web)", remote)
+
+	// Closing the pipe when the game ends frees pumpInput if it is blocked
+	// handing over a message nobody will read.
+	input, inputWriter := io.Pipe()
+	defer input.Close()
+	go s.pumpInput(ws, inputWriter)
+
+	console := game.Console{
+		In:       input,
+		Out:      ws,
+		Terminal: game.ANSITerminal{Out: ws},
+	}
+
+	session := game.NewSession(s.config, console, s.agent)
+	session.RememberLastPlayer = false
+	session.AllowModelPull = false
+	session.AllowModelSwitch = false
+	session.Play("")
+}
+
+// allowedOrigin stops other sites' pages from opening sessions in a
+// visitor's browser. Requests without an Origin do not come from a browser
+// page and are let through, as are the game's own page and the origins in
+// server.web_origins.
+func (s *Server) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range strings.Split(s.config.Server.WebOrigins, ",") {
+		allowed = strings.TrimSuffix(strings.TrimSpace(allowed), "/")
+		if allowed == "*" || (allowed != "" && strings.EqualFold(allowed, origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pumpInput feeds the player's messages into the game until the socket
+// closes or goes idle.
+func (s *Server) pumpInput(ws *wsConn, w *io.PipeWriter) {
+	for {
+		if timeout := s.config.Server.IdleTimeout; timeout > 0 {
+			ws.conn.SetReadDeadline(time.Now().Add(timeout))
+		}
+
+		message, err := ws.ReadMessage()
+		if err != nil {
+			w.CloseWithError(io.EOF)
+			return
+		}
+
+		if _, err := w.Write(message); err != nil {
+			return
+		}
+	}
+}